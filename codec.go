@@ -0,0 +1,59 @@
+package scs
+
+import (
+	"bytes"
+	"encoding/gob"
+	"time"
+)
+
+// Codec controls how session data is encoded and decoded for storage.
+// SessionManager.Codec can be set to a custom implementation (for example
+// to use JSON instead of gob) but defaults to GobCodec when left unset.
+type Codec interface {
+	Encode(deadline time.Time, values map[string]interface{}) ([]byte, error)
+	Decode(b []byte) (deadline time.Time, values map[string]interface{}, err error)
+}
+
+// GobCodec is the default Codec used by SessionManager. It encodes session
+// data using the standard library's encoding/gob package.
+type GobCodec struct{}
+
+// Encode serializes a session's deadline and values using encoding/gob.
+func (GobCodec) Encode(deadline time.Time, values map[string]interface{}) ([]byte, error) {
+	var b bytes.Buffer
+
+	enc := gob.NewEncoder(&b)
+	if err := enc.Encode(&deadline); err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(&values); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// Decode deserializes a session's deadline and values from their
+// encoding/gob representation.
+func (GobCodec) Decode(b []byte) (time.Time, map[string]interface{}, error) {
+	dec := gob.NewDecoder(bytes.NewReader(b))
+
+	var deadline time.Time
+	if err := dec.Decode(&deadline); err != nil {
+		return time.Time{}, nil, err
+	}
+
+	var values map[string]interface{}
+	if err := dec.Decode(&values); err != nil {
+		return time.Time{}, nil, err
+	}
+
+	return deadline, values, nil
+}
+
+func (s *SessionManager) codec() Codec {
+	if s.Codec != nil {
+		return s.Codec
+	}
+	return GobCodec{}
+}
@@ -0,0 +1,47 @@
+package scs
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+type contextKey string
+
+var contextKeyID int64
+
+func generateContextKey() contextKey {
+	id := atomic.AddInt64(&contextKeyID, 1)
+	return contextKey(fmt.Sprintf("scs.%d", id))
+}
+
+// managerContextKey is a single, package-wide context key (as opposed to
+// contextKey, which is generated per SessionManager instance) used to
+// recover whichever SessionManager's LoadAndSave loaded the session data
+// present on a request's context. SessionInput's Resolve method uses it
+// so that handlers can embed a *Session without being told explicitly
+// which SessionManager it came from.
+type managerContextKeyType struct{}
+
+var managerContextKey = managerContextKeyType{}
+
+func (s *SessionManager) addManagerToContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, managerContextKey, s)
+}
+
+func managerFromContext(ctx context.Context) (*SessionManager, bool) {
+	s, ok := ctx.Value(managerContextKey).(*SessionManager)
+	return s, ok
+}
+
+func (s *SessionManager) addSessionDataToContext(ctx context.Context, sd *sessionData) context.Context {
+	return context.WithValue(ctx, s.contextKey, sd)
+}
+
+func (s *SessionManager) getSessionDataFromContext(ctx context.Context) *sessionData {
+	c, ok := ctx.Value(s.contextKey).(*sessionData)
+	if !ok {
+		panic("scs: no session data in context")
+	}
+	return c
+}
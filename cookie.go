@@ -0,0 +1,45 @@
+package scs
+
+import "net/http"
+
+// SessionCookie contains the configuration settings for the session cookie
+// that is set by LoadAndSave.
+type SessionCookie struct {
+	// Name sets the name of the cookie used to store the session token.
+	// Defaults to "session" if not set.
+	Name string
+
+	// Domain sets the 'Domain' attribute on the session cookie. By default
+	// it will be left unset.
+	Domain string
+
+	// HttpOnly sets the 'HttpOnly' attribute on the session cookie. The
+	// default value is true.
+	HttpOnly bool
+
+	// Path sets the 'Path' attribute on the session cookie. The default
+	// value is "/". Passing the empty string "" will result in it being set
+	// to the path that the cookie was issued from.
+	Path string
+
+	// Persist sets whether the session cookie should be persistent or not
+	// (i.e. whether it should be retained after a user closes their
+	// browser). The default value is true, which means that the session
+	// cookie will not be destroyed when the user closes their browser and
+	// the appropriate 'Expires' and 'MaxAge' values will be added to the
+	// session cookie. Set this to false to to create a 'session' cookie -
+	// i.e. a cookie which is deleted when a user closes their browser.
+	Persist bool
+
+	// SameSite controls the value of the 'SameSite' attribute on the
+	// session cookie. By default this is set to 'SameSite=Lax'. If you
+	// want to change this you will need to import "net/http" into your
+	// project to use the relevant constant (i.e. http.SameSiteStrictMode,
+	// http.SameSiteLaxMode or http.SameSiteNoneMode).
+	SameSite http.SameSite
+
+	// Secure sets the 'Secure' attribute on the session cookie. The default
+	// value is false. It's recommended that you set this to true and serve
+	// all requests over HTTPS in production environments.
+	Secure bool
+}
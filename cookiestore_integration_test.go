@@ -0,0 +1,218 @@
+package scs
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+
+	"github.com/spa5k/scs/stores/cookiestore"
+)
+
+// newCookieStoreSessionManager returns a SessionManager backed by a
+// cookiestore.CookieStore, so that tests exercise the SelfContainedStore
+// code paths in Load/Commit instead of the default memstore.
+func newCookieStoreSessionManager(t *testing.T) *SessionManager {
+	t.Helper()
+
+	kr, err := cookiestore.NewKeyring(bytes.Repeat([]byte{1}, cookiestore.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionManager := New()
+	sessionManager.Store = cookiestore.New(kr)
+	return sessionManager
+}
+
+func TestCookieStoreLoadAndSaveRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := newCookieStoreSessionManager(t)
+
+	_, api := humatest.New(t)
+	addRoutes(api, sessionManager)
+
+	putResp := api.Put("/put")
+	token := extractTokenFromCookie(putResp.Header().Get("Set-Cookie"))
+	if token == "" {
+		t.Fatal("no session token found in PUT response")
+	}
+
+	getResp := api.Get("/get", "Cookie: session="+token)
+	if getResp.Code != http.StatusOK {
+		t.Fatalf("want status %d; got %d", http.StatusOK, getResp.Code)
+	}
+
+	responseBody := strings.Trim(getResp.Body.String(), "\"\n\r\t ")
+	if responseBody != "bar" {
+		t.Errorf("want value %q; got %q", "bar", responseBody)
+	}
+}
+
+func TestCookieStoreRenewToken(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := newCookieStoreSessionManager(t)
+
+	_, api := humatest.New(t)
+	api.UseMiddleware(sessionManager.LoadAndSave)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPut,
+		Path:   "/put",
+	}, func(ctx context.Context, input *struct {
+		Cookie string `header:"Cookie"`
+	}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		sessionManager.Put(ctx, "foo", "bar")
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPost,
+		Path:   "/renew",
+	}, func(ctx context.Context, input *struct {
+		Session string `cookie:"session"`
+	}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		if err := sessionManager.RenewToken(ctx); err != nil {
+			return nil, huma.NewError(http.StatusInternalServerError, err.Error())
+		}
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/get",
+	}, func(ctx context.Context, input *struct {
+		Session string `cookie:"session"`
+	}) (*struct {
+		Status int    `json:"status"`
+		Body   string `json:"body"`
+	}, error) {
+		v := sessionManager.Get(ctx, "foo")
+		if v == nil {
+			return nil, huma.NewError(http.StatusInternalServerError, "foo does not exist in session")
+		}
+		return &struct {
+			Status int    `json:"status"`
+			Body   string `json:"body"`
+		}{Status: http.StatusOK, Body: v.(string)}, nil
+	})
+
+	putResp := api.Put("/put")
+	originalToken := extractTokenFromCookie(putResp.Header().Get("Set-Cookie"))
+	if originalToken == "" {
+		t.Fatal("no session token found in PUT response")
+	}
+
+	renewResp := api.Post("/renew", "Cookie: session="+originalToken)
+	newToken := extractTokenFromCookie(renewResp.Header().Get("Set-Cookie"))
+	if newToken == "" {
+		t.Fatal("no session token found in renew response")
+	}
+	if newToken == originalToken {
+		t.Fatal("token has not changed")
+	}
+
+	getResp := api.Get("/get", "Cookie: session="+newToken)
+	if getResp.Code != http.StatusOK {
+		t.Fatalf("want status %d; got %d", http.StatusOK, getResp.Code)
+	}
+
+	responseBody := strings.Trim(getResp.Body.String(), "\"\n\r\t ")
+	if responseBody != "bar" {
+		t.Errorf("want value %q; got %q", "bar", responseBody)
+	}
+}
+
+func TestCookieStoreDestroy(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := newCookieStoreSessionManager(t)
+
+	_, api := humatest.New(t)
+	api.UseMiddleware(sessionManager.LoadAndSave)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPut,
+		Path:   "/put",
+	}, func(ctx context.Context, input *struct {
+		Cookie string `header:"Cookie"`
+	}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		sessionManager.Put(ctx, "foo", "bar")
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPost,
+		Path:   "/destroy",
+	}, func(ctx context.Context, input *struct {
+		Session string `cookie:"session"`
+	}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		if err := sessionManager.Destroy(ctx); err != nil {
+			return nil, huma.NewError(http.StatusInternalServerError, err.Error())
+		}
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/get",
+	}, func(ctx context.Context, input *struct {
+		Session string `cookie:"session"`
+	}) (*struct {
+		Status int    `json:"status"`
+		Body   string `json:"body"`
+	}, error) {
+		v := sessionManager.Get(ctx, "foo")
+		if v == nil {
+			return nil, huma.NewError(http.StatusInternalServerError, "foo does not exist in session")
+		}
+		return &struct {
+			Status int    `json:"status"`
+			Body   string `json:"body"`
+		}{Status: http.StatusOK, Body: v.(string)}, nil
+	})
+
+	putResp := api.Put("/put")
+	token := extractTokenFromCookie(putResp.Header().Get("Set-Cookie"))
+	if token == "" {
+		t.Fatal("no session token found in PUT response")
+	}
+
+	destroyResp := api.Post("/destroy", "Cookie: session="+token)
+	cookie := destroyResp.Header().Get("Set-Cookie")
+	if !strings.Contains(cookie, "Max-Age=0") {
+		t.Fatalf("got %q: expected to contain %q", cookie, "Max-Age=0")
+	}
+
+	// Since cookiestore never had server-side state for Destroy to remove,
+	// the session ends only once the client actually drops the expired
+	// cookie: a request without it starts a new, empty session. The
+	// original sealed token is not revoked and still opens until its own
+	// expiry.
+	getResp := api.Get("/get")
+	if getResp.Code != http.StatusInternalServerError {
+		t.Errorf("want status %d; got %d", http.StatusInternalServerError, getResp.Code)
+	}
+}
@@ -0,0 +1,180 @@
+package scs
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// csrfTokenKey is the reserved session key used to store the per-session
+// CSRF token. It lives inside the ordinary session value map (rather than
+// a side channel) so that it is transparently persisted, renewed and
+// expired using the same Store and Codec as the rest of the session data.
+const csrfTokenKey = "_csrf"
+
+// CSRFConfig holds the configuration for the double-submit CSRF
+// protection provided by VerifyCSRF.
+type CSRFConfig struct {
+	// HeaderName is the request header that VerifyCSRF compares against
+	// the token bound to the session. Defaults to "X-XSRF-Token".
+	HeaderName string
+
+	// CookieName, when set, additionally exposes the CSRF token to the
+	// client via a non-HttpOnly cookie of this name (for example
+	// "XSRF-TOKEN"), refreshed alongside the session cookie by
+	// LoadAndSave. This mirrors the convention used by Angular's
+	// HttpClient and axios, which read the token straight out of
+	// document.cookie and echo it back on HeaderName automatically.
+	// Leave empty to disable the companion cookie.
+	CookieName string
+
+	// Enabled records whether VerifyCSRF has been wired up as middleware
+	// for this SessionManager. VerifyCSRF itself doesn't consult this
+	// field, so setting it doesn't turn CSRF checking on or off; set it
+	// to true alongside registering VerifyCSRF so that RegisterOpenAPI
+	// knows to document the paired CSRF header security scheme.
+	Enabled bool
+}
+
+var csrfUnsafeMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+func newCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// CSRFToken returns the CSRF token bound to the current session,
+// generating and storing one under the reserved csrfTokenKey session key
+// if it doesn't already have one. The token is stable for the lifetime of
+// the session and only changes when RenewToken is called.
+func (s *SessionManager) CSRFToken(ctx context.Context) (string, error) {
+	if tok := s.GetString(ctx, csrfTokenKey); tok != "" {
+		return tok, nil
+	}
+
+	tok, err := newCSRFToken()
+	if err != nil {
+		return "", err
+	}
+	s.Put(ctx, csrfTokenKey, tok)
+
+	return tok, nil
+}
+
+// VerifyCSRF is Huma middleware implementing the double-submit cookie
+// pattern: it rejects any unsafe request (POST, PUT, PATCH or DELETE)
+// whose HeaderName header doesn't match the CSRF token bound to the
+// loaded session. Register it after LoadAndSave, since it relies on
+// session data already being present in the request context.
+func (s *SessionManager) VerifyCSRF(ctx huma.Context, next func(huma.Context)) {
+	if !csrfUnsafeMethods[ctx.Method()] {
+		next(ctx)
+		return
+	}
+
+	want, err := s.CSRFToken(ctx.Context())
+	if err != nil {
+		s.handleError(ctx, err)
+		return
+	}
+
+	got := ctx.Header(s.csrfHeaderName())
+	if got == "" || subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+		ctx.SetStatus(http.StatusForbidden)
+		_, _ = ctx.BodyWriter().Write([]byte(`{"title":"Forbidden","status":403,"detail":"CSRF token missing or invalid"}`))
+		return
+	}
+
+	next(ctx)
+}
+
+func (s *SessionManager) csrfHeaderName() string {
+	if s.CSRF.HeaderName != "" {
+		return s.CSRF.HeaderName
+	}
+	return "X-XSRF-Token"
+}
+
+// CSRFOutput can be embedded in a Huma operation's output struct so the
+// current session's CSRF token is surfaced to the client as a response
+// header, for SPAs that can't read the companion cookie (or don't want
+// to enable it). Populate it with NewCSRFOutput.
+//
+// The header name is fixed at CSRFOutput's default of "X-XSRF-Token",
+// matching csrfHeaderName's own default, because Huma resolves header
+// tags from the static Go type rather than per-SessionManager instance.
+// If CSRF.HeaderName is customized, CSRFOutput keeps using the default
+// name regardless; applications that need the header name itself to be
+// configurable should rely on the companion cookie (CSRF.CookieName)
+// instead, which is written dynamically by writeCSRFCookie.
+type CSRFOutput struct {
+	CSRFToken string `header:"X-XSRF-Token"`
+}
+
+// NewCSRFOutput builds a CSRFOutput carrying the CSRF token for the
+// current session, generating one first if necessary.
+func (s *SessionManager) NewCSRFOutput(ctx context.Context) (CSRFOutput, error) {
+	tok, err := s.CSRFToken(ctx)
+	if err != nil {
+		return CSRFOutput{}, err
+	}
+	return CSRFOutput{CSRFToken: tok}, nil
+}
+
+// writeCSRFCookie refreshes the non-HttpOnly companion CSRF cookie
+// alongside the session cookie, if CSRF.CookieName is configured and a
+// CSRF token has actually been issued for this session.
+func (s *SessionManager) writeCSRFCookie(ctx huma.Context, expiry http.Cookie) {
+	if s.CSRF.CookieName == "" {
+		return
+	}
+
+	tok := s.GetString(ctx.Context(), csrfTokenKey)
+	if tok == "" {
+		return
+	}
+
+	cookie := expiry
+	cookie.Name = s.CSRF.CookieName
+	cookie.Value = tok
+	cookie.HttpOnly = false
+
+	ctx.AppendHeader("Set-Cookie", cookie.String())
+}
+
+// writeExpiredCSRFCookie clears the companion CSRF cookie written by
+// writeCSRFCookie, if CSRF.CookieName is configured. It is called
+// alongside writeExpiredCookie when a session is destroyed, so a stale
+// CSRF token doesn't survive logout.
+func (s *SessionManager) writeExpiredCSRFCookie(ctx huma.Context) {
+	if s.CSRF.CookieName == "" {
+		return
+	}
+
+	cookie := &http.Cookie{
+		Name:     s.CSRF.CookieName,
+		Value:    "",
+		Path:     s.Cookie.Path,
+		Domain:   s.Cookie.Domain,
+		Secure:   s.Cookie.Secure,
+		HttpOnly: false,
+		SameSite: s.Cookie.SameSite,
+		Expires:  time.Unix(1, 0),
+		MaxAge:   -1,
+	}
+
+	ctx.AppendHeader("Set-Cookie", cookie.String())
+}
@@ -0,0 +1,269 @@
+package scs
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+)
+
+func addCSRFRoutes(api huma.API, sessionManager *SessionManager) {
+	api.UseMiddleware(sessionManager.LoadAndSave)
+	api.UseMiddleware(sessionManager.VerifyCSRF)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/token",
+	}, func(ctx context.Context, input *struct {
+		Session string `cookie:"session"`
+	}) (*struct {
+		CSRFOutput
+		Body struct {
+			Status int `json:"status"`
+		}
+	}, error) {
+		out, err := sessionManager.NewCSRFOutput(ctx)
+		if err != nil {
+			return nil, huma.NewError(http.StatusInternalServerError, err.Error())
+		}
+		resp := &struct {
+			CSRFOutput
+			Body struct {
+				Status int `json:"status"`
+			}
+		}{CSRFOutput: out}
+		resp.Body.Status = http.StatusOK
+		return resp, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPut,
+		Path:   "/protected",
+	}, func(ctx context.Context, input *struct {
+		Session string `cookie:"session"`
+	}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPost,
+		Path:   "/renew",
+	}, func(ctx context.Context, input *struct {
+		Session string `cookie:"session"`
+	}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		if err := sessionManager.RenewToken(ctx); err != nil {
+			return nil, huma.NewError(http.StatusInternalServerError, err.Error())
+		}
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPost,
+		Path:   "/logout",
+	}, func(ctx context.Context, input *struct {
+		Session string `cookie:"session"`
+	}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		if err := sessionManager.Destroy(ctx); err != nil {
+			return nil, huma.NewError(http.StatusInternalServerError, err.Error())
+		}
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+}
+
+func TestVerifyCSRFAllowsSafeMethods(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := New()
+	_, api := humatest.New(t)
+	addCSRFRoutes(api, sessionManager)
+
+	resp := api.Get("/token")
+	if resp.Code != http.StatusOK {
+		t.Errorf("want status %d; got %d", http.StatusOK, resp.Code)
+	}
+}
+
+func TestVerifyCSRFRejectsMissingOrWrongHeader(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := New()
+	_, api := humatest.New(t)
+	addCSRFRoutes(api, sessionManager)
+
+	tokenResp := api.Get("/token")
+	token := extractTokenFromCookie(tokenResp.Header().Get("Set-Cookie"))
+
+	missingResp := api.Put("/protected", "Cookie: session="+token)
+	if missingResp.Code != http.StatusForbidden {
+		t.Errorf("want status %d; got %d", http.StatusForbidden, missingResp.Code)
+	}
+
+	wrongResp := api.Put("/protected", "Cookie: session="+token, "X-XSRF-Token: bogus")
+	if wrongResp.Code != http.StatusForbidden {
+		t.Errorf("want status %d; got %d", http.StatusForbidden, wrongResp.Code)
+	}
+}
+
+func TestVerifyCSRFAcceptsMatchingHeader(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := New()
+	_, api := humatest.New(t)
+	addCSRFRoutes(api, sessionManager)
+
+	tokenResp := api.Get("/token")
+	token := extractTokenFromCookie(tokenResp.Header().Get("Set-Cookie"))
+	csrfToken := tokenResp.Header().Get("X-Xsrf-Token")
+	if csrfToken == "" {
+		t.Fatal("no CSRF token found in response header")
+	}
+
+	protectedResp := api.Put("/protected", "Cookie: session="+token, "X-XSRF-Token: "+csrfToken)
+	if protectedResp.Code != http.StatusOK {
+		t.Errorf("want status %d; got %d", http.StatusOK, protectedResp.Code)
+	}
+}
+
+func TestRenewTokenRotatesCSRFToken(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := New()
+	_, api := humatest.New(t)
+	addCSRFRoutes(api, sessionManager)
+
+	tokenResp := api.Get("/token")
+	token := extractTokenFromCookie(tokenResp.Header().Get("Set-Cookie"))
+	originalCSRFToken := tokenResp.Header().Get("X-Xsrf-Token")
+
+	renewResp := api.Post("/renew", "Cookie: session="+token, "X-XSRF-Token: "+originalCSRFToken)
+	newToken := extractTokenFromCookie(renewResp.Header().Get("Set-Cookie"))
+
+	newTokenResp := api.Get("/token", "Cookie: session="+newToken)
+	newCSRFToken := newTokenResp.Header().Get("X-Xsrf-Token")
+	if newCSRFToken == "" {
+		t.Fatal("no CSRF token found in response header")
+	}
+	if newCSRFToken == originalCSRFToken {
+		t.Fatal("CSRF token did not change after RenewToken")
+	}
+
+	// The old CSRF token must no longer be accepted.
+	staleResp := api.Put("/protected", "Cookie: session="+newToken, "X-XSRF-Token: "+originalCSRFToken)
+	if staleResp.Code != http.StatusForbidden {
+		t.Errorf("want status %d; got %d", http.StatusForbidden, staleResp.Code)
+	}
+}
+
+func TestVerifyCSRFCompanionCookie(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := New()
+	sessionManager.CSRF.CookieName = "XSRF-TOKEN"
+	_, api := humatest.New(t)
+	addCSRFRoutes(api, sessionManager)
+
+	resp := api.Get("/token")
+	csrfToken := resp.Header().Get("X-Xsrf-Token")
+
+	var companionCookie string
+	for _, cookie := range resp.Header().Values("Set-Cookie") {
+		if strings.HasPrefix(cookie, "XSRF-TOKEN=") {
+			companionCookie = cookie
+		}
+	}
+	if companionCookie == "" {
+		t.Fatalf("want %q cookie in response; got %q", "XSRF-TOKEN", strings.Join(resp.Header().Values("Set-Cookie"), ", "))
+	}
+	if !strings.Contains(companionCookie, csrfToken) {
+		t.Errorf("want companion cookie value %q; got %q", csrfToken, companionCookie)
+	}
+	if strings.Contains(companionCookie, "HttpOnly") {
+		t.Error("want companion cookie not HttpOnly")
+	}
+}
+
+func TestRenewTokenSyncsCompanionCSRFCookie(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := New()
+	sessionManager.CSRF.CookieName = "XSRF-TOKEN"
+	_, api := humatest.New(t)
+	addCSRFRoutes(api, sessionManager)
+
+	tokenResp := api.Get("/token")
+	token := extractTokenFromCookie(tokenResp.Header().Get("Set-Cookie"))
+	originalCSRFToken := tokenResp.Header().Get("X-Xsrf-Token")
+
+	renewResp := api.Post("/renew", "Cookie: session="+token, "X-XSRF-Token: "+originalCSRFToken)
+	if renewResp.Code != http.StatusOK {
+		t.Fatalf("want status %d; got %d", http.StatusOK, renewResp.Code)
+	}
+	newToken := extractTokenFromCookie(renewResp.Header().Get("Set-Cookie"))
+
+	var companionCookie string
+	for _, cookie := range renewResp.Header().Values("Set-Cookie") {
+		if strings.HasPrefix(cookie, "XSRF-TOKEN=") {
+			companionCookie = cookie
+		}
+	}
+	if companionCookie == "" {
+		t.Fatalf("want a refreshed %q cookie in the renew response; got %q", "XSRF-TOKEN", strings.Join(renewResp.Header().Values("Set-Cookie"), ", "))
+	}
+	if strings.Contains(companionCookie, originalCSRFToken) {
+		t.Errorf("want companion cookie to carry the rotated CSRF token, not the original; got %q", companionCookie)
+	}
+
+	// The CSRF token in the fresh companion cookie must actually be the one
+	// the server now expects.
+	newCompanionToken := strings.TrimPrefix(strings.SplitN(companionCookie, ";", 2)[0], "XSRF-TOKEN=")
+	protectedResp := api.Put("/protected", "Cookie: session="+newToken, "X-XSRF-Token: "+newCompanionToken)
+	if protectedResp.Code != http.StatusOK {
+		t.Errorf("want status %d using the renewed companion cookie's token; got %d", http.StatusOK, protectedResp.Code)
+	}
+}
+
+func TestDestroyClearsCompanionCSRFCookie(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := New()
+	sessionManager.CSRF.CookieName = "XSRF-TOKEN"
+	_, api := humatest.New(t)
+	addCSRFRoutes(api, sessionManager)
+
+	tokenResp := api.Get("/token")
+	token := extractTokenFromCookie(tokenResp.Header().Get("Set-Cookie"))
+	csrfToken := tokenResp.Header().Get("X-Xsrf-Token")
+
+	logoutResp := api.Post("/logout", "Cookie: session="+token, "X-XSRF-Token: "+csrfToken)
+	if logoutResp.Code != http.StatusOK {
+		t.Fatalf("want status %d; got %d", http.StatusOK, logoutResp.Code)
+	}
+
+	var companionCookie string
+	for _, cookie := range logoutResp.Header().Values("Set-Cookie") {
+		if strings.HasPrefix(cookie, "XSRF-TOKEN=") {
+			companionCookie = cookie
+		}
+	}
+	if companionCookie == "" {
+		t.Fatalf("want %q cookie cleared on logout; got %q", "XSRF-TOKEN", strings.Join(logoutResp.Header().Values("Set-Cookie"), ", "))
+	}
+	if !strings.Contains(companionCookie, "Max-Age=0") {
+		t.Errorf("want companion cookie expired; got %q", companionCookie)
+	}
+}
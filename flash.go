@@ -0,0 +1,109 @@
+package scs
+
+import (
+	"context"
+	"encoding/gob"
+)
+
+// flashKey is the reserved session key under which flash message buckets
+// are stored. Like csrfTokenKey, it lives inside the ordinary session
+// value map so that flashes are persisted, and expired, alongside the
+// rest of the session data by the same Store and Codec.
+const flashKey = "_flash"
+
+func init() {
+	// Registered so GobCodec, the default Codec, can encode the flash
+	// bucket map stored under flashKey; encoding/gob requires concrete
+	// types reachable through an interface{} to be registered up front.
+	gob.Register(map[string][]interface{}{})
+}
+
+// AddFlash queues msg as a one-shot flash message on the current session,
+// to be returned (and removed) the next time Flashes or NewFlashOutput is
+// called for the same key. If key is omitted, the message is queued in
+// the default bucket. Multiple named buckets (for example "error" and
+// "info") can be used to group flashes by kind.
+//
+// This mirrors session.AddFlash/session.Flashes as implemented by
+// gorilla/sessions and negroni-sessions.
+func (s *SessionManager) AddFlash(ctx context.Context, msg interface{}, key ...string) {
+	bucket := flashBucket(key)
+
+	sd := s.getSessionDataFromContext(ctx)
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	flashes, _ := sd.values[flashKey].(map[string][]interface{})
+	if flashes == nil {
+		flashes = make(map[string][]interface{})
+	}
+	flashes[bucket] = append(flashes[bucket], msg)
+	sd.values[flashKey] = flashes
+	sd.status = Modified
+}
+
+// Flashes returns every flash message queued under key (the default
+// bucket, if key is omitted), removing them from the session in the same
+// operation. It returns nil if there are none.
+func (s *SessionManager) Flashes(ctx context.Context, key ...string) []interface{} {
+	bucket := flashBucket(key)
+
+	sd := s.getSessionDataFromContext(ctx)
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	flashes, ok := sd.values[flashKey].(map[string][]interface{})
+	if !ok || len(flashes[bucket]) == 0 {
+		return nil
+	}
+
+	msgs := flashes[bucket]
+	delete(flashes, bucket)
+	if len(flashes) == 0 {
+		delete(sd.values, flashKey)
+	} else {
+		sd.values[flashKey] = flashes
+	}
+	sd.status = Modified
+
+	return msgs
+}
+
+func flashBucket(key []string) string {
+	if len(key) > 0 {
+		return key[0]
+	}
+	return ""
+}
+
+// Flash pairs a drained flash message with the bucket key it was queued
+// under, as returned in a FlashOutput.
+type Flash struct {
+	Key     string      `json:"key,omitempty"`
+	Message interface{} `json:"message"`
+}
+
+// FlashOutput can be embedded in a Huma operation's output struct so
+// queued flash messages are automatically drained and returned to the
+// client. Populate it with NewFlashOutput.
+type FlashOutput struct {
+	Flashes []Flash `json:"flashes,omitempty"`
+}
+
+// NewFlashOutput builds a FlashOutput carrying every flash message queued
+// under the given bucket keys (the default bucket, if none are given),
+// draining each bucket from the session in the process.
+func (s *SessionManager) NewFlashOutput(ctx context.Context, keys ...string) FlashOutput {
+	if len(keys) == 0 {
+		keys = []string{""}
+	}
+
+	var flashes []Flash
+	for _, key := range keys {
+		for _, msg := range s.Flashes(ctx, key) {
+			flashes = append(flashes, Flash{Key: key, Message: msg})
+		}
+	}
+
+	return FlashOutput{Flashes: flashes}
+}
@@ -0,0 +1,70 @@
+package scs
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+)
+
+func TestAddFlashCommitsWithDefaultCodec(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := New()
+
+	_, api := humatest.New(t)
+	api.UseMiddleware(sessionManager.LoadAndSave)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPut,
+		Path:   "/add-flash",
+	}, func(ctx context.Context, input *struct{}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		sessionManager.AddFlash(ctx, "welcome back")
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/flashes",
+	}, func(ctx context.Context, input *struct {
+		Session string `cookie:"session"`
+	}) (*struct {
+		Body FlashOutput
+	}, error) {
+		return &struct {
+			Body FlashOutput
+		}{Body: sessionManager.NewFlashOutput(ctx)}, nil
+	})
+
+	// AddFlash followed by a normal LoadAndSave commit must round-trip
+	// through the default GobCodec without a registration error.
+	addResp := api.Put("/add-flash")
+	if addResp.Code != http.StatusOK {
+		t.Fatalf("want status %d; got %d", http.StatusOK, addResp.Code)
+	}
+	token := extractTokenFromCookie(addResp.Header().Get("Set-Cookie"))
+
+	flashResp := api.Get("/flashes", "Cookie: session="+token)
+	if flashResp.Code != http.StatusOK {
+		t.Fatalf("want status %d; got %d", http.StatusOK, flashResp.Code)
+	}
+	if !strings.Contains(flashResp.Body.String(), "welcome back") {
+		t.Errorf("want flash message in response; got %q", flashResp.Body.String())
+	}
+
+	// Flashes are drained, so a second request sees none.
+	secondResp := api.Get("/flashes", "Cookie: session="+token)
+	if secondResp.Code != http.StatusOK {
+		t.Fatalf("want status %d; got %d", http.StatusOK, secondResp.Code)
+	}
+	if strings.Contains(secondResp.Body.String(), "welcome back") {
+		t.Errorf("want flashes drained; got %q", secondResp.Body.String())
+	}
+}
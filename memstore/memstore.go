@@ -0,0 +1,118 @@
+// Package memstore provides an in-memory implementation of the scs.Store
+// interface. It is the default store used by scs.New(), and is only
+// suitable for applications running from a single process (it will not
+// share sessions across multiple instances, and data is lost on restart).
+package memstore
+
+import (
+	"sync"
+	"time"
+)
+
+// MemStore represents the session store, and contains the underlying map of
+// session data.
+type MemStore struct {
+	mu sync.Mutex
+	m  map[string]item
+}
+
+type item struct {
+	object     []byte
+	expiry     int64
+	lastAccess int64
+}
+
+// New returns a new MemStore instance, with a background cleanup goroutine
+// that runs every minute to remove expired session data.
+func New() *MemStore {
+	return NewWithCleanupInterval(time.Minute)
+}
+
+// NewWithCleanupInterval returns a new MemStore instance. The cleanupInterval
+// parameter controls how frequently expired session data is removed by the
+// background cleanup goroutine. Setting it to 0 disables the background
+// cleanup goroutine.
+func NewWithCleanupInterval(cleanupInterval time.Duration) *MemStore {
+	m := &MemStore{m: make(map[string]item)}
+	if cleanupInterval > 0 {
+		go m.startCleanup(cleanupInterval)
+	}
+	return m
+}
+
+// Find returns the data for a given session token from the MemStore
+// instance. If the session token is not found or is expired, the returned
+// exists flag will be false.
+func (m *MemStore) Find(token string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i, found := m.m[token]
+	if !found || time.Now().UnixNano() > i.expiry {
+		return nil, false, nil
+	}
+
+	return i.object, true, nil
+}
+
+// Commit adds a session token and data to the MemStore instance with the
+// given expiry time. If the session token already exists then the data and
+// expiry time are updated.
+func (m *MemStore) Commit(token string, b []byte, expiry time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.m[token] = item{
+		object:     b,
+		expiry:     expiry.UnixNano(),
+		lastAccess: time.Now().UnixNano(),
+	}
+
+	return nil
+}
+
+// Delete removes a session token and its data from the MemStore instance.
+func (m *MemStore) Delete(token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.m, token)
+
+	return nil
+}
+
+// All returns a map containing the token and data for all active (i.e.
+// not expired) sessions in the MemStore instance.
+func (m *MemStore) All() (map[string][]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sessions := make(map[string][]byte)
+
+	for token, i := range m.m {
+		if time.Now().UnixNano() < i.expiry {
+			sessions[token] = i.object
+		}
+	}
+
+	return sessions, nil
+}
+
+func (m *MemStore) startCleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	for range ticker.C {
+		m.deleteExpired()
+	}
+}
+
+func (m *MemStore) deleteExpired() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	for token, i := range m.m {
+		if now > i.expiry {
+			delete(m.m, token)
+		}
+	}
+}
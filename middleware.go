@@ -0,0 +1,261 @@
+package scs
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// LoadAndSave provides middleware which automatically loads and saves
+// session data for the current request, and communicates the session
+// token to and from the client in a cookie. It should be registered with
+// api.UseMiddleware before any operations that call SessionManager
+// methods are registered.
+func (s *SessionManager) LoadAndSave(ctx huma.Context, next func(huma.Context)) {
+	token := s.tokenFromCookieHeader(ctx.Header("Cookie"))
+
+	sessionCtx, err := s.Load(ctx.Context(), token)
+	if err != nil {
+		s.handleError(ctx, err)
+		return
+	}
+	ctx = huma.WithContext(ctx, s.addManagerToContext(sessionCtx))
+
+	next(ctx)
+
+	switch s.Status(ctx.Context()) {
+	case Modified:
+		newToken, expiry, err := s.Commit(ctx.Context())
+		if err != nil {
+			s.handleError(ctx, err)
+			return
+		}
+		s.writeSessionCookie(ctx, newToken, expiry)
+	case Destroyed:
+		if token != "" {
+			if _, ok := s.Store.(SelfContainedStore); !ok {
+				if err := s.storeDelete(ctx.Context(), token); err != nil {
+					s.handleError(ctx, err)
+					return
+				}
+			}
+		}
+		s.writeExpiredCookie(ctx)
+		s.writeExpiredCSRFCookie(ctx)
+	}
+}
+
+// Load retrieves the session data for the given token from the Store and
+// returns a new context.Context with that session data attached. An empty
+// token, or one that is not found (or has expired) in the Store, results
+// in a brand new, empty session being started.
+//
+// Most applications should rely on the LoadAndSave middleware rather than
+// calling Load directly; it is exported primarily for use from
+// non-request contexts such as background jobs, where SessionManager
+// methods still need a context carrying session data (for example in
+// combination with Iterate).
+func (s *SessionManager) Load(ctx context.Context, token string) (context.Context, error) {
+	if token == "" {
+		return s.addSessionDataToContext(ctx, newSessionData(s.Lifetime, s.Cookie.Persist)), nil
+	}
+
+	if scStore, ok := s.Store.(SelfContainedStore); ok {
+		deadline, values, found, err := scStore.Open(token)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return s.addSessionDataToContext(ctx, newSessionData(s.Lifetime, s.Cookie.Persist)), nil
+		}
+
+		sd := &sessionData{
+			status:    Unmodified,
+			token:     token,
+			origToken: token,
+			deadline:  deadline,
+			persist:   s.Cookie.Persist,
+			values:    values,
+		}
+		return s.addSessionDataToContext(ctx, sd), nil
+	}
+
+	b, found, err := s.storeFind(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return s.addSessionDataToContext(ctx, newSessionData(s.Lifetime, s.Cookie.Persist)), nil
+	}
+
+	deadline, values, err := s.codec().Decode(b)
+	if err != nil {
+		return nil, err
+	}
+
+	sd := &sessionData{
+		status:    Unmodified,
+		token:     token,
+		origToken: token,
+		deadline:  deadline,
+		persist:   s.Cookie.Persist,
+		values:    values,
+	}
+
+	return s.addSessionDataToContext(ctx, sd), nil
+}
+
+// Commit saves the session data back to the Store (generating a new
+// session token first, if necessary) and returns the token and expiry
+// time that should be communicated back to the client.
+func (s *SessionManager) Commit(ctx context.Context) (string, time.Time, error) {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	expiry := sd.deadline
+	if s.IdleTimeout > 0 {
+		ie := time.Now().Add(s.IdleTimeout)
+		if ie.Before(expiry) {
+			expiry = ie
+		}
+	}
+
+	if scStore, ok := s.Store.(SelfContainedStore); ok {
+		token, err := scStore.Seal(sd.deadline, expiry, sd.values)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		sd.token = token
+		return sd.token, expiry, nil
+	}
+
+	if sd.token == "" {
+		token, err := generateToken()
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		sd.token = token
+	}
+
+	b, err := s.codec().Encode(sd.deadline, sd.values)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	if err := s.storeCommit(ctx, sd.token, b, expiry); err != nil {
+		return "", time.Time{}, err
+	}
+
+	// RenewToken clears sd.token so that a fresh one is generated above; if
+	// that happened, the old token's data is now a stale duplicate in the
+	// Store and must be removed so it can't go on being used to access the
+	// session (the whole point of RenewToken as a fixation defense).
+	if sd.origToken != "" && sd.origToken != sd.token {
+		if err := s.storeDelete(ctx, sd.origToken); err != nil {
+			return "", time.Time{}, err
+		}
+	}
+	sd.origToken = sd.token
+
+	return sd.token, expiry, nil
+}
+
+// storeFind prefers Store.FindCtx when the configured Store implements
+// CtxStore, so that request cancellation and request-scoped values (e.g. a
+// database transaction) reach the backend; it falls back to the plain
+// Store.Find otherwise. Callers must only reach storeFind when s.Store is
+// not a SelfContainedStore, so it is safe to assume it implements Store.
+func (s *SessionManager) storeFind(ctx context.Context, token string) ([]byte, bool, error) {
+	if cs, ok := s.Store.(CtxStore); ok {
+		return cs.FindCtx(ctx, token)
+	}
+	return s.Store.(Store).Find(token)
+}
+
+// storeCommit is the Commit-side equivalent of storeFind.
+func (s *SessionManager) storeCommit(ctx context.Context, token string, b []byte, expiry time.Time) error {
+	if cs, ok := s.Store.(CtxStore); ok {
+		return cs.CommitCtx(ctx, token, b, expiry)
+	}
+	return s.Store.(Store).Commit(token, b, expiry)
+}
+
+// storeDelete is the Delete-side equivalent of storeFind.
+func (s *SessionManager) storeDelete(ctx context.Context, token string) error {
+	if cs, ok := s.Store.(CtxStore); ok {
+		return cs.DeleteCtx(ctx, token)
+	}
+	return s.Store.(Store).Delete(token)
+}
+
+func (s *SessionManager) tokenFromCookieHeader(cookieHeader string) string {
+	if cookieHeader == "" {
+		return ""
+	}
+
+	r := &http.Request{Header: http.Header{"Cookie": []string{cookieHeader}}}
+	c, err := r.Cookie(s.Cookie.Name)
+	if err != nil {
+		return ""
+	}
+
+	return c.Value
+}
+
+func (s *SessionManager) writeSessionCookie(ctx huma.Context, token string, expiry time.Time) {
+	sd := s.getSessionDataFromContext(ctx.Context())
+	sd.mu.Lock()
+	persist := sd.persist
+	sd.mu.Unlock()
+
+	cookie := http.Cookie{
+		Name:     s.Cookie.Name,
+		Value:    token,
+		Path:     s.Cookie.Path,
+		Domain:   s.Cookie.Domain,
+		Secure:   s.Cookie.Secure,
+		HttpOnly: s.Cookie.HttpOnly,
+		SameSite: s.Cookie.SameSite,
+	}
+
+	if persist {
+		cookie.Expires = time.Unix(expiry.Unix()+1, 0)
+		cookie.MaxAge = int(time.Until(expiry).Seconds() + 1)
+	}
+
+	ctx.AppendHeader("Set-Cookie", cookie.String())
+	s.writeCSRFCookie(ctx, cookie)
+}
+
+func (s *SessionManager) writeExpiredCookie(ctx huma.Context) {
+	cookie := &http.Cookie{
+		Name:     s.Cookie.Name,
+		Value:    "",
+		Path:     s.Cookie.Path,
+		Domain:   s.Cookie.Domain,
+		Secure:   s.Cookie.Secure,
+		HttpOnly: s.Cookie.HttpOnly,
+		SameSite: s.Cookie.SameSite,
+		Expires:  time.Unix(1, 0),
+		MaxAge:   -1,
+	}
+
+	ctx.AppendHeader("Set-Cookie", cookie.String())
+}
+
+// handleError is invoked whenever Load or Commit fail while processing a
+// request. It defers to ErrorFunc when one is configured, otherwise it
+// responds with an opaque 500 Internal Server Error.
+func (s *SessionManager) handleError(ctx huma.Context, err error) {
+	if s.ErrorFunc != nil {
+		s.ErrorFunc(ctx, err)
+		return
+	}
+
+	ctx.SetStatus(http.StatusInternalServerError)
+	_, _ = ctx.BodyWriter().Write([]byte(`{"title":"Internal Server Error","status":500,"detail":"session error"}`))
+}
@@ -0,0 +1,219 @@
+package scs
+
+import (
+	"context"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// OpenAPIConfig controls how RegisterOpenAPI documents the session (and,
+// if enabled, CSRF) cookies in the generated OpenAPI document.
+type OpenAPIConfig struct {
+	// SchemeName is the name under which the session cookie is registered
+	// in components.securitySchemes. Defaults to "sessionCookie".
+	SchemeName string
+
+	// CSRFSchemeName is the name under which the CSRF header is
+	// registered in components.securitySchemes, if CSRF.Enabled is true.
+	// Defaults to "csrfHeader".
+	CSRFSchemeName string
+
+	// Default, if true, installs the documented scheme(s) as the
+	// OpenAPI document's default security requirement (api.OpenAPI().
+	// Security), which Huma applies to every operation in the document
+	// that doesn't declare its own Security, regardless of whether that
+	// operation was registered before or after RegisterOpenAPI is
+	// called.
+	Default bool
+}
+
+// RegisterOpenAPI documents the session cookie (and, if CSRF.Enabled is
+// set, the paired CSRF header) as OpenAPI security schemes, so operations
+// protected by LoadAndSave and VerifyCSRF don't each have to redeclare a
+// Cookie or CSRF header input purely to appear in the generated document.
+//
+// It should be called once during setup, after Cookie and CSRF have been
+// configured and before the API is served.
+func (s *SessionManager) RegisterOpenAPI(api huma.API, config ...OpenAPIConfig) {
+	cfg := OpenAPIConfig{
+		SchemeName:     "sessionCookie",
+		CSRFSchemeName: "csrfHeader",
+	}
+	if len(config) > 0 {
+		cfg = config[0]
+		if cfg.SchemeName == "" {
+			cfg.SchemeName = "sessionCookie"
+		}
+		if cfg.CSRFSchemeName == "" {
+			cfg.CSRFSchemeName = "csrfHeader"
+		}
+	}
+
+	components := api.OpenAPI().Components
+	if components.SecuritySchemes == nil {
+		components.SecuritySchemes = map[string]*huma.SecurityScheme{}
+	}
+
+	components.SecuritySchemes[cfg.SchemeName] = &huma.SecurityScheme{
+		Type: "apiKey",
+		In:   "cookie",
+		Name: s.Cookie.Name,
+	}
+
+	security := []map[string][]string{{cfg.SchemeName: {}}}
+
+	if s.CSRF.Enabled {
+		components.SecuritySchemes[cfg.CSRFSchemeName] = &huma.SecurityScheme{
+			Type: "apiKey",
+			In:   "header",
+			Name: s.csrfHeaderName(),
+		}
+		security = append(security, map[string][]string{cfg.CSRFSchemeName: {}})
+	}
+
+	if cfg.Default {
+		api.OpenAPI().Security = security
+	}
+}
+
+// Session provides access to the current request's session data without
+// having to thread a context.Context through every SessionManager call.
+// It is populated by SessionInput, for handlers that would rather embed
+// a typed field than call the SessionManager directly.
+type Session struct {
+	ctx            context.Context
+	sessionManager *SessionManager
+}
+
+// Put adds a key and corresponding value to the session data. See
+// SessionManager.Put.
+func (sess *Session) Put(key string, val interface{}) {
+	sess.sessionManager.Put(sess.ctx, key, val)
+}
+
+// Get returns the value for a given key from the session data. See
+// SessionManager.Get.
+func (sess *Session) Get(key string) interface{} {
+	return sess.sessionManager.Get(sess.ctx, key)
+}
+
+// Pop acts like a one-time Get. See SessionManager.Pop.
+func (sess *Session) Pop(key string) interface{} {
+	return sess.sessionManager.Pop(sess.ctx, key)
+}
+
+// Remove deletes the given key and corresponding value from the session
+// data. See SessionManager.Remove.
+func (sess *Session) Remove(key string) {
+	sess.sessionManager.Remove(sess.ctx, key)
+}
+
+// Clear removes all data for the current session. See SessionManager.Clear.
+func (sess *Session) Clear() error {
+	return sess.sessionManager.Clear(sess.ctx)
+}
+
+// Exists returns true if the given key is present in the session data.
+// See SessionManager.Exists.
+func (sess *Session) Exists(key string) bool {
+	return sess.sessionManager.Exists(sess.ctx, key)
+}
+
+// Keys returns a slice of all key names present in the session data,
+// sorted alphabetically. See SessionManager.Keys.
+func (sess *Session) Keys() []string {
+	return sess.sessionManager.Keys(sess.ctx)
+}
+
+// Token returns the session token for the current session. See
+// SessionManager.Token.
+func (sess *Session) Token() string {
+	return sess.sessionManager.Token(sess.ctx)
+}
+
+// GetString returns the string value for a given key. See
+// SessionManager.GetString.
+func (sess *Session) GetString(key string) string {
+	return sess.sessionManager.GetString(sess.ctx, key)
+}
+
+// GetBool returns the bool value for a given key. See
+// SessionManager.GetBool.
+func (sess *Session) GetBool(key string) bool {
+	return sess.sessionManager.GetBool(sess.ctx, key)
+}
+
+// GetInt returns the int value for a given key. See SessionManager.GetInt.
+func (sess *Session) GetInt(key string) int {
+	return sess.sessionManager.GetInt(sess.ctx, key)
+}
+
+// GetFloat64 returns the float64 value for a given key. See
+// SessionManager.GetFloat64.
+func (sess *Session) GetFloat64(key string) float64 {
+	return sess.sessionManager.GetFloat64(sess.ctx, key)
+}
+
+// GetBytes returns the []byte value for a given key. See
+// SessionManager.GetBytes.
+func (sess *Session) GetBytes(key string) []byte {
+	return sess.sessionManager.GetBytes(sess.ctx, key)
+}
+
+// GetTime returns the time.Time value for a given key. See
+// SessionManager.GetTime.
+func (sess *Session) GetTime(key string) time.Time {
+	return sess.sessionManager.GetTime(sess.ctx, key)
+}
+
+// AddFlash queues a one-shot flash message on the session. See
+// SessionManager.AddFlash.
+func (sess *Session) AddFlash(msg interface{}, key ...string) {
+	sess.sessionManager.AddFlash(sess.ctx, msg, key...)
+}
+
+// Flashes returns and drains the flash messages queued for a bucket. See
+// SessionManager.Flashes.
+func (sess *Session) Flashes(key ...string) []interface{} {
+	return sess.sessionManager.Flashes(sess.ctx, key...)
+}
+
+// RenewToken updates the session to have a new token while retaining its
+// data. See SessionManager.RenewToken.
+func (sess *Session) RenewToken() error {
+	return sess.sessionManager.RenewToken(sess.ctx)
+}
+
+// Destroy deletes the current session data and clears the session
+// cookie. See SessionManager.Destroy.
+func (sess *Session) Destroy() error {
+	return sess.sessionManager.Destroy(sess.ctx)
+}
+
+// RememberMe controls whether the session cookie issued for the current
+// request should be persistent. See SessionManager.RememberMe.
+func (sess *Session) RememberMe(persist bool) {
+	sess.sessionManager.RememberMe(sess.ctx, persist)
+}
+
+// SessionInput can be embedded in a huma.Register input struct so the
+// handler receives a populated Session field instead of calling
+// SessionManager methods directly with a context.Context. It requires
+// LoadAndSave to have run for the request, since that's what attaches
+// both the session data and the owning SessionManager to the context.
+type SessionInput struct {
+	Session *Session `json:"-"`
+}
+
+// Resolve implements huma.Resolver, populating Session from the request
+// context once the rest of the input has been parsed.
+func (si *SessionInput) Resolve(ctx huma.Context) []error {
+	sm, ok := managerFromContext(ctx.Context())
+	if !ok {
+		return nil
+	}
+
+	si.Session = &Session{ctx: ctx.Context(), sessionManager: sm}
+	return nil
+}
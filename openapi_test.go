@@ -0,0 +1,160 @@
+package scs
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+)
+
+func TestRegisterOpenAPIDefaultSchemeNames(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := New()
+	_, api := humatest.New(t)
+
+	sessionManager.RegisterOpenAPI(api)
+
+	schemes := api.OpenAPI().Components.SecuritySchemes
+	scheme, ok := schemes["sessionCookie"]
+	if !ok {
+		t.Fatal("want sessionCookie security scheme registered")
+	}
+	if scheme.In != "cookie" || scheme.Name != sessionManager.Cookie.Name {
+		t.Errorf("want cookie scheme for %q; got %+v", sessionManager.Cookie.Name, scheme)
+	}
+	if _, ok := schemes["csrfHeader"]; ok {
+		t.Error("want no csrfHeader scheme when CSRF.Enabled is false")
+	}
+	if api.OpenAPI().Security != nil {
+		t.Errorf("want no default security requirement; got %v", api.OpenAPI().Security)
+	}
+}
+
+func TestRegisterOpenAPICSRFSchemeAndDefault(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := New()
+	sessionManager.CSRF.Enabled = true
+	_, api := humatest.New(t)
+
+	sessionManager.RegisterOpenAPI(api, OpenAPIConfig{
+		SchemeName:     "mySession",
+		CSRFSchemeName: "myCSRF",
+		Default:        true,
+	})
+
+	schemes := api.OpenAPI().Components.SecuritySchemes
+	if _, ok := schemes["mySession"]; !ok {
+		t.Fatal("want mySession security scheme registered")
+	}
+	csrfScheme, ok := schemes["myCSRF"]
+	if !ok {
+		t.Fatal("want myCSRF security scheme registered")
+	}
+	if csrfScheme.In != "header" || csrfScheme.Name != sessionManager.csrfHeaderName() {
+		t.Errorf("want header scheme for %q; got %+v", sessionManager.csrfHeaderName(), csrfScheme)
+	}
+
+	security := api.OpenAPI().Security
+	if len(security) != 2 {
+		t.Fatalf("want default security requiring both schemes; got %v", security)
+	}
+}
+
+func TestRegisterOpenAPIDefaultAppliesRegardlessOfRegistrationOrder(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := New()
+	_, api := humatest.New(t)
+
+	// Register an operation before RegisterOpenAPI runs. Default applies
+	// to api.OpenAPI().Security, a single document-level field, so it
+	// covers operations on both sides of the RegisterOpenAPI call.
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/before",
+	}, func(ctx context.Context, input *struct{}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	sessionManager.RegisterOpenAPI(api, OpenAPIConfig{Default: true})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/after",
+	}, func(ctx context.Context, input *struct{}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	before := api.OpenAPI().Paths["/before"].Get
+	after := api.OpenAPI().Paths["/after"].Get
+	if len(before.Security) != 0 || len(after.Security) != 0 {
+		t.Fatalf("want neither operation to carry its own Security (default is document-level); got before=%v after=%v", before.Security, after.Security)
+	}
+	if len(api.OpenAPI().Security) != 1 {
+		t.Fatalf("want document-level default security set; got %v", api.OpenAPI().Security)
+	}
+}
+
+func TestSessionInputResolve(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := New()
+	_, api := humatest.New(t)
+	api.UseMiddleware(sessionManager.LoadAndSave)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPut,
+		Path:   "/put",
+	}, func(ctx context.Context, input *struct {
+		SessionInput
+	}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		input.Session.Put("foo", "bar")
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/get",
+	}, func(ctx context.Context, input *struct {
+		SessionInput
+	}) (*struct {
+		Status int    `json:"status"`
+		Body   string `json:"body"`
+	}, error) {
+		v := input.Session.Get("foo")
+		if v == nil {
+			return nil, huma.NewError(http.StatusInternalServerError, "foo does not exist in session")
+		}
+		return &struct {
+			Status int    `json:"status"`
+			Body   string `json:"body"`
+		}{Status: http.StatusOK, Body: v.(string)}, nil
+	})
+
+	putResp := api.Put("/put")
+	if putResp.Code != http.StatusOK {
+		t.Fatalf("want status %d; got %d", http.StatusOK, putResp.Code)
+	}
+	token := extractTokenFromCookie(putResp.Header().Get("Set-Cookie"))
+
+	getResp := api.Get("/get", "Cookie: session="+token)
+	if getResp.Code != http.StatusOK {
+		t.Fatalf("want status %d; got %d", http.StatusOK, getResp.Code)
+	}
+}
@@ -0,0 +1,491 @@
+// Package scs provides session management for Huma-based APIs. It is
+// modelled closely on github.com/alexedwards/scs, but the middleware is
+// written against huma.Context instead of net/http, so session state can be
+// loaded, mutated and saved around Huma operations without every handler
+// having to manage cookies itself.
+package scs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/spa5k/scs/memstore"
+)
+
+// Status represents the state of the session data during a request cycle.
+type Status int
+
+const (
+	// Unmodified indicates that the session data hasn't been changed in the
+	// current request cycle.
+	Unmodified Status = iota
+	// Modified indicates that the session data has been changed in the
+	// current request cycle.
+	Modified
+	// Destroyed indicates that the session data has been destroyed in the
+	// current request cycle.
+	Destroyed
+)
+
+// SessionManager holds the configuration settings for your sessions.
+type SessionManager struct {
+	// Store controls the session storage backend. If not set, it defaults
+	// to an in-memory store, which is not suitable for applications
+	// running on more than one instance. It must implement Store (and,
+	// optionally, CtxStore/IterableStore) for a server-side backend, or
+	// SelfContainedStore for one that embeds the session in the cookie
+	// itself (for example stores/cookiestore). It isn't declared as the
+	// Store interface so that a SelfContainedStore-only implementation,
+	// which has no Find/Commit/Delete methods to satisfy it, can be
+	// assigned here too.
+	Store interface{}
+
+	// Codec controls how session data is encoded before it is passed to
+	// Store.Commit, and decoded after it is returned from Store.Find. If
+	// not set, it defaults to GobCodec, which uses the standard library's
+	// encoding/gob package.
+	Codec Codec
+
+	// Lifetime controls the maximum length of time that a session is valid
+	// for before it expires. The lifetime is an 'absolute' expiry time,
+	// and is not extended by further requests. Defaults to 24 hours.
+	Lifetime time.Duration
+
+	// IdleTimeout controls the maximum length of time a session can be
+	// inactive before it expires. This is not set by default, meaning that
+	// there is no idle timeout and a session can be left inactive for any
+	// length of time up to the Lifetime. The IdleTimeout expiry time is
+	// only refreshed when the session data is written (i.e. whenever
+	// LoadAndSave commits the session), not on every request; a
+	// read-only request does not reset it.
+	IdleTimeout time.Duration
+
+	// Cookie contains the configuration settings for the session cookie.
+	Cookie SessionCookie
+
+	// CSRF contains the configuration settings for the built-in
+	// double-submit CSRF protection enforced by VerifyCSRF.
+	CSRF CSRFConfig
+
+	// ErrorFunc allows you to control behavior when an error is encountered
+	// loading or saving a session by LoadAndSave. If not set, the default
+	// behavior is to write a 500 Internal Server Error response via Huma.
+	ErrorFunc func(ctx huma.Context, err error)
+
+	// contextKey is used internally so that multiple SessionManager
+	// instances can coexist in the same context tree without clobbering
+	// each other's data.
+	contextKey contextKey
+}
+
+// New returns a new SessionManager with the default configuration settings.
+// It should only be called once per application; the same SessionManager
+// instance can (and should) be used across multiple goroutines and
+// requests.
+func New() *SessionManager {
+	s := &SessionManager{
+		Store:       memstore.New(),
+		Lifetime:    24 * time.Hour,
+		Cookie: SessionCookie{
+			Name:     "session",
+			Domain:   "",
+			HttpOnly: true,
+			Path:     "/",
+			Persist:  true,
+			SameSite: http.SameSiteLaxMode,
+			Secure:   false,
+		},
+		CSRF: CSRFConfig{
+			HeaderName: "X-XSRF-Token",
+		},
+		contextKey: generateContextKey(),
+	}
+
+	return s
+}
+
+// sessionData represents the data for a single session.
+type sessionData struct {
+	mu        sync.Mutex
+	deadline  time.Time
+	status    Status
+	token     string
+	origToken string
+	persist   bool
+	values    map[string]interface{}
+}
+
+func newSessionData(lifetime time.Duration, persist bool) *sessionData {
+	return &sessionData{
+		deadline: time.Now().Add(lifetime).UTC(),
+		status:   Unmodified,
+		persist:  persist,
+		values:   make(map[string]interface{}),
+	}
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b), nil
+}
+
+// Put adds a key and corresponding value to the session data. Any existing
+// value for the key will be replaced.
+func (s *SessionManager) Put(ctx context.Context, key string, val interface{}) {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	sd.values[key] = val
+	sd.status = Modified
+}
+
+// Get returns the value for a given key from the session data. The
+// returned value has the type interface{}, so will usually need to be type
+// asserted before you can use it.
+func (s *SessionManager) Get(ctx context.Context, key string) interface{} {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	return sd.values[key]
+}
+
+// Pop acts like a one-time Get. It returns the value for a given key from
+// the session data and deletes it from the session data in one operation.
+func (s *SessionManager) Pop(ctx context.Context, key string) interface{} {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	val, exists := sd.values[key]
+	if !exists {
+		return nil
+	}
+	delete(sd.values, key)
+	sd.status = Modified
+
+	return val
+}
+
+// Remove deletes the given key and corresponding value from the session
+// data. If the key is not present this operation is a no-op.
+func (s *SessionManager) Remove(ctx context.Context, key string) {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	if _, exists := sd.values[key]; !exists {
+		return
+	}
+
+	delete(sd.values, key)
+	sd.status = Modified
+}
+
+// Clear removes all data for the current session from the session data.
+func (s *SessionManager) Clear(ctx context.Context) error {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	if len(sd.values) == 0 {
+		return nil
+	}
+
+	for key := range sd.values {
+		delete(sd.values, key)
+	}
+	sd.status = Modified
+
+	return nil
+}
+
+// Exists returns true if the given key is present in the session data.
+func (s *SessionManager) Exists(ctx context.Context, key string) bool {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	_, exists := sd.values[key]
+	return exists
+}
+
+// Keys returns a slice of all key names present in the session data,
+// sorted alphabetically.
+func (s *SessionManager) Keys(ctx context.Context) []string {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	keys := make([]string, len(sd.values))
+	i := 0
+	for key := range sd.values {
+		keys[i] = key
+		i++
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Status returns the current status of the session data.
+func (s *SessionManager) Status(ctx context.Context) Status {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	return sd.status
+}
+
+// Deadline returns the 'absolute' expiry time for the session.
+func (s *SessionManager) Deadline(ctx context.Context) time.Time {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	return sd.deadline
+}
+
+// Token returns the session token for the current session. Note that for a
+// new session this will be the empty string until the session has been
+// committed (i.e. the response has been written). RenewToken also clears
+// the token, so after it has been called Token returns the empty string
+// until the renewed session has been committed.
+func (s *SessionManager) Token(ctx context.Context) string {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	return sd.token
+}
+
+// GetString returns the string value for a given key from the session
+// data. The zero value for a string ("") is returned if the key does not
+// exist or the value could not be type asserted to a string.
+func (s *SessionManager) GetString(ctx context.Context, key string) string {
+	val := s.Get(ctx, key)
+	str, ok := val.(string)
+	if !ok {
+		return ""
+	}
+	return str
+}
+
+// GetBool returns the bool value for a given key from the session data.
+// The zero value for a bool (false) is returned if the key does not exist
+// or the value could not be type asserted to a bool.
+func (s *SessionManager) GetBool(ctx context.Context, key string) bool {
+	val := s.Get(ctx, key)
+	b, ok := val.(bool)
+	if !ok {
+		return false
+	}
+	return b
+}
+
+// GetInt returns the int value for a given key from the session data. The
+// zero value for an int (0) is returned if the key does not exist or the
+// value could not be type asserted to an int.
+func (s *SessionManager) GetInt(ctx context.Context, key string) int {
+	val := s.Get(ctx, key)
+	i, ok := val.(int)
+	if !ok {
+		return 0
+	}
+	return i
+}
+
+// GetFloat64 returns the float64 value for a given key from the session
+// data. The zero value for a float64 (0.0) is returned if the key does not
+// exist or the value could not be type asserted to a float64.
+func (s *SessionManager) GetFloat64(ctx context.Context, key string) float64 {
+	val := s.Get(ctx, key)
+	f, ok := val.(float64)
+	if !ok {
+		return 0
+	}
+	return f
+}
+
+// GetBytes returns the byte slice ([]byte) value for a given key from the
+// session data. The zero value for a slice (nil) is returned if the key
+// does not exist or could not be type asserted to []byte.
+func (s *SessionManager) GetBytes(ctx context.Context, key string) []byte {
+	val := s.Get(ctx, key)
+	b, ok := val.([]byte)
+	if !ok {
+		return nil
+	}
+	return b
+}
+
+// GetTime returns the time.Time value for a given key from the session
+// data. The zero value for a time.Time object is returned if the key does
+// not exist or the value could not be type asserted to a time.Time.
+func (s *SessionManager) GetTime(ctx context.Context, key string) time.Time {
+	val := s.Get(ctx, key)
+	t, ok := val.(time.Time)
+	if !ok {
+		return time.Time{}
+	}
+	return t
+}
+
+// RenewToken updates the session data to have a new session token while
+// retaining the current session data. The session lifetime is also reset.
+// If the session already had a CSRF token bound to it (see CSRFToken), a
+// fresh one is minted immediately so the response that rotates the
+// session also carries a synced companion CSRF cookie, rather than
+// leaving the client holding a CSRF token the server has discarded.
+//
+// The old session token and accompanying data are deleted from the session
+// store once the response has been written (via LoadAndSave).
+//
+// To mitigate the risk of session fixation attacks, it's important that
+// you call RenewToken before making any changes to privilege levels (e.g.
+// login and logout operations). See
+// https://www.owasp.org/index.php/Session_Management_Cheat_Sheet#Renew_the_Session_ID_After_Any_Privilege_Level_Change
+// for additional information.
+func (s *SessionManager) RenewToken(ctx context.Context) error {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	_, hadCSRFToken := sd.values[csrfTokenKey]
+
+	sd.token = ""
+	sd.deadline = time.Now().Add(s.Lifetime).UTC()
+	sd.status = Modified
+	delete(sd.values, csrfTokenKey)
+
+	if hadCSRFToken {
+		tok, err := newCSRFToken()
+		if err != nil {
+			return err
+		}
+		sd.values[csrfTokenKey] = tok
+	}
+
+	return nil
+}
+
+// Destroy deletes the current session data from the session store, and
+// sets the response to clear the session cookie. Any further operations in
+// the same request cycle will operate on an empty session, and a new
+// session cookie will be set if data is subsequently added to it.
+//
+// Destroy should be called before any response is written to the request
+// (in particular, it cannot be used once the response has been committed
+// by LoadAndSave).
+func (s *SessionManager) Destroy(ctx context.Context) error {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	sd.status = Destroyed
+
+	sd.token = ""
+	sd.deadline = time.Now().Add(s.Lifetime).UTC()
+	for key := range sd.values {
+		delete(sd.values, key)
+	}
+
+	return nil
+}
+
+// RememberMe controls whether the session cookie issued for the current
+// request should be persistent (i.e. outlive the browser session) or not,
+// overriding the manager-wide Cookie.Persist setting for this session only.
+func (s *SessionManager) RememberMe(ctx context.Context, persist bool) {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	sd.persist = persist
+	if sd.status == Unmodified {
+		sd.status = Modified
+	}
+}
+
+// Iterate retrieves all active (i.e. not expired) sessions from the
+// session store and executes the provided function fn for each session in
+// turn. Each call to fn receives a context.Context with the data for the
+// relevant session already loaded into it, so calls to scs.SessionManager
+// methods (Get, Put, Destroy and so on) referencing that context will
+// operate on that session. If fn modifies or destroys the session, that
+// change is committed to the Store (or deleted from it) once fn returns,
+// exactly as LoadAndSave would do at the end of an HTTP request - this is
+// what makes it safe to call Destroy from within fn to implement sweeps
+// like "log out user X everywhere".
+//
+// Iterate requires the SessionManager's Store to implement the
+// IterableStore interface.
+func (s *SessionManager) Iterate(ctx context.Context, fn func(context.Context) error) error {
+	iterableStore, ok := s.Store.(IterableStore)
+	if !ok {
+		return fmt.Errorf("scs: %T does not support iteration (must implement IterableStore)", s.Store)
+	}
+
+	allSessions, err := iterableStore.All()
+	if err != nil {
+		return err
+	}
+
+	for token, b := range allSessions {
+		deadline, values, err := s.codec().Decode(b)
+		if err != nil {
+			return err
+		}
+
+		sd := &sessionData{
+			status:    Unmodified,
+			token:     token,
+			origToken: token,
+			deadline:  deadline,
+			persist:   true,
+			values:    values,
+		}
+
+		itCtx := s.addSessionDataToContext(ctx, sd)
+		if err := fn(itCtx); err != nil {
+			return err
+		}
+
+		switch s.Status(itCtx) {
+		case Modified:
+			if _, _, err := s.Commit(itCtx); err != nil {
+				return err
+			}
+		case Destroyed:
+			if err := s.storeDelete(ctx, token); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -3,7 +3,12 @@ package scs
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -261,7 +266,8 @@ func TestIdleTimeout(t *testing.T) {
 
 	time.Sleep(100 * time.Millisecond)
 
-	// First GET request
+	// A read-only request does not refresh the idle timeout, so the
+	// session set by the PUT 100ms ago is still alive.
 	getResp1 := api.Get("/get", "Cookie: session="+token)
 	if getResp1.Code != http.StatusOK {
 		t.Errorf("want status %d; got %d", http.StatusOK, getResp1.Code)
@@ -269,18 +275,11 @@ func TestIdleTimeout(t *testing.T) {
 
 	time.Sleep(150 * time.Millisecond)
 
-	// Second GET request
+	// The idle timeout was last refreshed by the PUT 250ms ago, so the
+	// session has now expired even though it was read in between.
 	getResp2 := api.Get("/get", "Cookie: session="+token)
-	if getResp2.Code != http.StatusOK {
-		t.Errorf("want status %d; got %d", http.StatusOK, getResp2.Code)
-	}
-
-	time.Sleep(200 * time.Millisecond)
-
-	// Third GET request
-	getResp3 := api.Get("/get", "Cookie: session="+token)
-	if getResp3.Code != http.StatusInternalServerError {
-		t.Errorf("want status %d; got %d", http.StatusInternalServerError, getResp3.Code)
+	if getResp2.Code != http.StatusInternalServerError {
+		t.Errorf("want status %d; got %d", http.StatusInternalServerError, getResp2.Code)
 	}
 
 	var errorResponse struct {
@@ -289,7 +288,7 @@ func TestIdleTimeout(t *testing.T) {
 		Detail string `json:"detail"`
 	}
 
-	err := json.Unmarshal(getResp3.Body.Bytes(), &errorResponse)
+	err := json.Unmarshal(getResp2.Body.Bytes(), &errorResponse)
 	if err != nil {
 		t.Fatalf("Failed to unmarshal error response: %v", err)
 	}
@@ -299,224 +298,321 @@ func TestIdleTimeout(t *testing.T) {
 	}
 }
 
-// func TestIdleTimeout(t *testing.T) {
-// 	t.Parallel()
-
-// 	sessionManager := New()
-// 	sessionManager.IdleTimeout = 200 * time.Millisecond
-// 	sessionManager.Lifetime = time.Second
-
-// 	mux := http.NewServeMux()
-// 	mux.HandleFunc("/put", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-// 		sessionManager.Put(r.Context(), "foo", "bar")
-// 	}))
-// 	mux.HandleFunc("/get", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-// 		v := sessionManager.Get(r.Context(), "foo")
-// 		if v == nil {
-// 			http.Error(w, "foo does not exist in session", 500)
-// 			return
-// 		}
-// 		w.Write([]byte(v.(string)))
-// 	}))
-
-// 	ts := newTestServer(t, sessionManager.LoadAndSave(mux))
-// 	defer ts.Close()
-
-// 	ts.execute(t, "/put")
-
-// 	time.Sleep(100 * time.Millisecond)
-// 	ts.execute(t, "/get")
-
-// 	time.Sleep(150 * time.Millisecond)
-// 	_, body := ts.execute(t, "/get")
-// 	if body != "bar" {
-// 		t.Errorf("want %q; got %q", "bar", body)
-// 	}
-
-// 	time.Sleep(200 * time.Millisecond)
-// 	_, body = ts.execute(t, "/get")
-// 	if body != "foo does not exist in session\n" {
-// 		t.Errorf("want %q; got %q", "foo does not exist in session\n", body)
-// 	}
-// }
-
-// func TestDestroy(t *testing.T) {
-// 	t.Parallel()
-
-// 	sessionManager := New()
-
-// 	mux := http.NewServeMux()
-// 	mux.HandleFunc("/put", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-// 		sessionManager.Put(r.Context(), "foo", "bar")
-// 	}))
-// 	mux.HandleFunc("/destroy", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-// 		err := sessionManager.Destroy(r.Context())
-// 		if err != nil {
-// 			http.Error(w, err.Error(), 500)
-// 			return
-// 		}
-// 	}))
-// 	mux.HandleFunc("/get", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-// 		v := sessionManager.Get(r.Context(), "foo")
-// 		if v == nil {
-// 			http.Error(w, "foo does not exist in session", 500)
-// 			return
-// 		}
-// 		w.Write([]byte(v.(string)))
-// 	}))
-
-// 	ts := newTestServer(t, sessionManager.LoadAndSave(mux))
-// 	defer ts.Close()
-
-// 	ts.execute(t, "/put")
-// 	header, _ := ts.execute(t, "/destroy")
-// 	cookie := header.Get("Set-Cookie")
-
-// 	if strings.HasPrefix(cookie, fmt.Sprintf("%s=;", sessionManager.Cookie.Name)) == false {
-// 		t.Fatalf("got %q: expected prefix %q", cookie, fmt.Sprintf("%s=;", sessionManager.Cookie.Name))
-// 	}
-// 	if strings.Contains(cookie, "Expires=Thu, 01 Jan 1970 00:00:01 GMT") == false {
-// 		t.Fatalf("got %q: expected to contain %q", cookie, "Expires=Thu, 01 Jan 1970 00:00:01 GMT")
-// 	}
-// 	if strings.Contains(cookie, "Max-Age=0") == false {
-// 		t.Fatalf("got %q: expected to contain %q", cookie, "Max-Age=0")
-// 	}
-
-// 	_, body := ts.execute(t, "/get")
-// 	if body != "foo does not exist in session\n" {
-// 		t.Errorf("want %q; got %q", "foo does not exist in session\n", body)
-// 	}
-// }
-
-// func TestRenewToken(t *testing.T) {
-// 	t.Parallel()
-
-// 	sessionManager := New()
-
-// 	mux := http.NewServeMux()
-// 	mux.HandleFunc("/put", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-// 		sessionManager.Put(r.Context(), "foo", "bar")
-// 	}))
-// 	mux.HandleFunc("/renew", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-// 		err := sessionManager.RenewToken(r.Context())
-// 		if err != nil {
-// 			http.Error(w, err.Error(), 500)
-// 			return
-// 		}
-// 	}))
-// 	mux.HandleFunc("/get", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-// 		v := sessionManager.Get(r.Context(), "foo")
-// 		if v == nil {
-// 			http.Error(w, "foo does not exist in session", 500)
-// 			return
-// 		}
-// 		w.Write([]byte(v.(string)))
-// 	}))
-
-// 	ts := newTestServer(t, sessionManager.LoadAndSave(mux))
-// 	defer ts.Close()
-
-// 	header, _ := ts.execute(t, "/put")
-// 	cookie := header.Get("Set-Cookie")
-// 	originalToken := extractTokenFromCookie(cookie)
-
-// 	header, _ = ts.execute(t, "/renew")
-// 	cookie = header.Get("Set-Cookie")
-// 	newToken := extractTokenFromCookie(cookie)
-
-// 	if newToken == originalToken {
-// 		t.Fatal("token has not changed")
-// 	}
-
-// 	_, body := ts.execute(t, "/get")
-// 	if body != "bar" {
-// 		t.Errorf("want %q; got %q", "bar", body)
-// 	}
-// }
-
-// func TestRememberMe(t *testing.T) {
-// 	t.Parallel()
-
-// 	sessionManager := New()
-// 	sessionManager.Cookie.Persist = false
-
-// 	mux := http.NewServeMux()
-// 	mux.HandleFunc("/put-normal", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-// 		sessionManager.Put(r.Context(), "foo", "bar")
-// 	}))
-// 	mux.HandleFunc("/put-rememberMe-true", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-// 		sessionManager.RememberMe(r.Context(), true)
-// 		sessionManager.Put(r.Context(), "foo", "bar")
-// 	}))
-// 	mux.HandleFunc("/put-rememberMe-false", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-// 		sessionManager.RememberMe(r.Context(), false)
-// 		sessionManager.Put(r.Context(), "foo", "bar")
-// 	}))
-
-// 	ts := newTestServer(t, sessionManager.LoadAndSave(mux))
-// 	defer ts.Close()
-
-// 	header, _ := ts.execute(t, "/put-normal")
-// 	header.Get("Set-Cookie")
-
-// 	if strings.Contains(header.Get("Set-Cookie"), "Max-Age=") || strings.Contains(header.Get("Set-Cookie"), "Expires=") {
-// 		t.Errorf("want no Max-Age or Expires attributes; got %q", header.Get("Set-Cookie"))
-// 	}
-
-// 	header, _ = ts.execute(t, "/put-rememberMe-true")
-// 	header.Get("Set-Cookie")
-
-// 	if !strings.Contains(header.Get("Set-Cookie"), "Max-Age=") || !strings.Contains(header.Get("Set-Cookie"), "Expires=") {
-// 		t.Errorf("want Max-Age and Expires attributes; got %q", header.Get("Set-Cookie"))
-// 	}
-
-// 	header, _ = ts.execute(t, "/put-rememberMe-false")
-// 	header.Get("Set-Cookie")
-
-// 	if strings.Contains(header.Get("Set-Cookie"), "Max-Age=") || strings.Contains(header.Get("Set-Cookie"), "Expires=") {
-// 		t.Errorf("want no Max-Age or Expires attributes; got %q", header.Get("Set-Cookie"))
-// 	}
-// }
-
-// func TestIterate(t *testing.T) {
-// 	t.Parallel()
-
-// 	sessionManager := New()
-
-// 	mux := http.NewServeMux()
-// 	mux.HandleFunc("/put", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-// 		sessionManager.Put(r.Context(), "foo", r.URL.Query().Get("foo"))
-// 	}))
-
-// 	for i := 0; i < 3; i++ {
-// 		ts := newTestServer(t, sessionManager.LoadAndSave(mux))
-// 		defer ts.Close()
-
-// 		ts.execute(t, "/put?foo="+strconv.Itoa(i))
-// 	}
-
-// 	results := []string{}
-
-// 	err := sessionManager.Iterate(context.Background(), func(ctx context.Context) error {
-// 		i := sessionManager.GetString(ctx, "foo")
-// 		results = append(results, i)
-// 		return nil
-// 	})
-
-// 	if err != nil {
-// 		t.Fatal(err)
-// 	}
-
-// 	sort.Strings(results)
-
-// 	if !reflect.DeepEqual(results, []string{"0", "1", "2"}) {
-// 		t.Fatalf("unexpected value: got %v", results)
-// 	}
-
-// 	err = sessionManager.Iterate(context.Background(), func(ctx context.Context) error {
-// 		return errors.New("expected error")
-// 	})
-// 	if err.Error() != "expected error" {
-// 		t.Fatal("didn't get expected error")
-// 	}
-// }
+func TestDestroy(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := New()
+
+	_, api := humatest.New(t)
+	api.UseMiddleware(sessionManager.LoadAndSave)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPut,
+		Path:   "/put",
+	}, func(ctx context.Context, input *struct {
+		Cookie string `header:"Cookie"`
+	}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		sessionManager.Put(ctx, "foo", "bar")
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPost,
+		Path:   "/destroy",
+	}, func(ctx context.Context, input *struct {
+		Session string `cookie:"session"`
+	}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		if err := sessionManager.Destroy(ctx); err != nil {
+			return nil, huma.NewError(http.StatusInternalServerError, err.Error())
+		}
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/get",
+	}, func(ctx context.Context, input *struct {
+		Session string `cookie:"session"`
+	}) (*struct {
+		Status int    `json:"status"`
+		Body   string `json:"body"`
+	}, error) {
+		v := sessionManager.Get(ctx, "foo")
+		if v == nil {
+			return nil, huma.NewError(http.StatusInternalServerError, "foo does not exist in session")
+		}
+		return &struct {
+			Status int    `json:"status"`
+			Body   string `json:"body"`
+		}{Status: http.StatusOK, Body: v.(string)}, nil
+	})
+
+	putResp := api.Put("/put")
+	token := extractTokenFromCookie(putResp.Header().Get("Set-Cookie"))
+	if token == "" {
+		t.Fatal("no session token found in PUT response")
+	}
+
+	destroyResp := api.Post("/destroy", "Cookie: session="+token)
+	cookie := destroyResp.Header().Get("Set-Cookie")
+
+	if !strings.HasPrefix(cookie, fmt.Sprintf("%s=;", sessionManager.Cookie.Name)) {
+		t.Fatalf("got %q: expected prefix %q", cookie, fmt.Sprintf("%s=;", sessionManager.Cookie.Name))
+	}
+	if !strings.Contains(cookie, "Expires=Thu, 01 Jan 1970 00:00:01 GMT") {
+		t.Fatalf("got %q: expected to contain %q", cookie, "Expires=Thu, 01 Jan 1970 00:00:01 GMT")
+	}
+	if !strings.Contains(cookie, "Max-Age=0") {
+		t.Fatalf("got %q: expected to contain %q", cookie, "Max-Age=0")
+	}
+
+	getResp := api.Get("/get", "Cookie: session="+token)
+	if getResp.Code != http.StatusInternalServerError {
+		t.Errorf("want status %d; got %d", http.StatusInternalServerError, getResp.Code)
+	}
+}
+
+func TestRenewToken(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := New()
+
+	_, api := humatest.New(t)
+	api.UseMiddleware(sessionManager.LoadAndSave)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPut,
+		Path:   "/put",
+	}, func(ctx context.Context, input *struct {
+		Cookie string `header:"Cookie"`
+	}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		sessionManager.Put(ctx, "foo", "bar")
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPost,
+		Path:   "/renew",
+	}, func(ctx context.Context, input *struct {
+		Session string `cookie:"session"`
+	}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		if err := sessionManager.RenewToken(ctx); err != nil {
+			return nil, huma.NewError(http.StatusInternalServerError, err.Error())
+		}
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodGet,
+		Path:   "/get",
+	}, func(ctx context.Context, input *struct {
+		Session string `cookie:"session"`
+	}) (*struct {
+		Status int    `json:"status"`
+		Body   string `json:"body"`
+	}, error) {
+		v := sessionManager.Get(ctx, "foo")
+		if v == nil {
+			return nil, huma.NewError(http.StatusInternalServerError, "foo does not exist in session")
+		}
+		return &struct {
+			Status int    `json:"status"`
+			Body   string `json:"body"`
+		}{Status: http.StatusOK, Body: v.(string)}, nil
+	})
+
+	putResp := api.Put("/put")
+	originalToken := extractTokenFromCookie(putResp.Header().Get("Set-Cookie"))
+	if originalToken == "" {
+		t.Fatal("no session token found in PUT response")
+	}
+
+	renewResp := api.Post("/renew", "Cookie: session="+originalToken)
+	newToken := extractTokenFromCookie(renewResp.Header().Get("Set-Cookie"))
+	if newToken == "" {
+		t.Fatal("no session token found in renew response")
+	}
+	if newToken == originalToken {
+		t.Fatal("token has not changed")
+	}
+
+	getResp := api.Get("/get", "Cookie: session="+newToken)
+	if getResp.Code != http.StatusOK {
+		t.Errorf("want status %d; got %d", http.StatusOK, getResp.Code)
+	}
+
+	responseBody := strings.Trim(getResp.Body.String(), "\"\n\r\t ")
+	if responseBody != "bar" {
+		t.Errorf("want value %q; got %q", "bar", responseBody)
+	}
+
+	// The pre-renewal token must be deleted from the Store, not just
+	// unlinked from the request: replaying it should no longer work.
+	replayResp := api.Get("/get", "Cookie: session="+originalToken)
+	if replayResp.Code != http.StatusInternalServerError {
+		t.Errorf("want status %d for the original, renewed-away token; got %d", http.StatusInternalServerError, replayResp.Code)
+	}
+}
+
+func TestRememberMe(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := New()
+	sessionManager.Cookie.Persist = false
+
+	_, api := humatest.New(t)
+	api.UseMiddleware(sessionManager.LoadAndSave)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPut,
+		Path:   "/put-normal",
+	}, func(ctx context.Context, input *struct {
+		Cookie string `header:"Cookie"`
+	}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		sessionManager.Put(ctx, "foo", "bar")
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPut,
+		Path:   "/put-remember-true",
+	}, func(ctx context.Context, input *struct {
+		Cookie string `header:"Cookie"`
+	}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		sessionManager.RememberMe(ctx, true)
+		sessionManager.Put(ctx, "foo", "bar")
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPut,
+		Path:   "/put-remember-false",
+	}, func(ctx context.Context, input *struct {
+		Cookie string `header:"Cookie"`
+	}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		sessionManager.RememberMe(ctx, false)
+		sessionManager.Put(ctx, "foo", "bar")
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	normalResp := api.Put("/put-normal")
+	normalCookie := normalResp.Header().Get("Set-Cookie")
+	if strings.Contains(normalCookie, "Max-Age=") || strings.Contains(normalCookie, "Expires=") {
+		t.Errorf("want no Max-Age or Expires attributes; got %q", normalCookie)
+	}
+
+	trueResp := api.Put("/put-remember-true")
+	trueCookie := trueResp.Header().Get("Set-Cookie")
+	if !strings.Contains(trueCookie, "Max-Age=") || !strings.Contains(trueCookie, "Expires=") {
+		t.Errorf("want Max-Age and Expires attributes; got %q", trueCookie)
+	}
+
+	falseResp := api.Put("/put-remember-false")
+	falseCookie := falseResp.Header().Get("Set-Cookie")
+	if strings.Contains(falseCookie, "Max-Age=") || strings.Contains(falseCookie, "Expires=") {
+		t.Errorf("want no Max-Age or Expires attributes; got %q", falseCookie)
+	}
+}
+
+func TestIterate(t *testing.T) {
+	t.Parallel()
+
+	sessionManager := New()
+
+	_, api := humatest.New(t)
+	api.UseMiddleware(sessionManager.LoadAndSave)
+
+	huma.Register(api, huma.Operation{
+		Method: http.MethodPut,
+		Path:   "/put",
+	}, func(ctx context.Context, input *struct {
+		Cookie string `header:"Cookie"`
+		Foo    string `query:"foo"`
+	}) (*struct {
+		Status int `json:"status"`
+	}, error) {
+		sessionManager.Put(ctx, "foo", input.Foo)
+		return &struct {
+			Status int `json:"status"`
+		}{Status: http.StatusOK}, nil
+	})
+
+	for i := 0; i < 3; i++ {
+		api.Put("/put?foo=" + strconv.Itoa(i))
+	}
+
+	results := []string{}
+	err := sessionManager.Iterate(context.Background(), func(ctx context.Context) error {
+		results = append(results, sessionManager.GetString(ctx, "foo"))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(results)
+	if !reflect.DeepEqual(results, []string{"0", "1", "2"}) {
+		t.Fatalf("unexpected value: got %v", results)
+	}
+
+	// Destroying a session from within the callback (e.g. to log a user
+	// out everywhere) must actually remove it from the Store.
+	err = sessionManager.Iterate(context.Background(), func(ctx context.Context) error {
+		if sessionManager.GetString(ctx, "foo") == "1" {
+			return sessionManager.Destroy(ctx)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remaining := []string{}
+	err = sessionManager.Iterate(context.Background(), func(ctx context.Context) error {
+		remaining = append(remaining, sessionManager.GetString(ctx, "foo"))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(remaining)
+	if !reflect.DeepEqual(remaining, []string{"0", "2"}) {
+		t.Fatalf("want session for foo=1 destroyed; got %v", remaining)
+	}
+
+	err = sessionManager.Iterate(context.Background(), func(ctx context.Context) error {
+		return errors.New("expected error")
+	})
+	if err == nil || err.Error() != "expected error" {
+		t.Fatal("didn't get expected error")
+	}
+}
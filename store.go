@@ -0,0 +1,76 @@
+package scs
+
+import (
+	"context"
+	"time"
+)
+
+// Store is the interface for session stores. A Store is responsible only
+// for persisting and retrieving the opaque, already-encoded session data
+// for a given token; it has no knowledge of what that data means.
+type Store interface {
+	// Delete removes a session token and its data from the store.
+	Delete(token string) (err error)
+
+	// Find returns the data for a session token, along with a boolean
+	// indicating whether the session was found (and is not expired) in the
+	// store.
+	Find(token string) (b []byte, exists bool, err error)
+
+	// Commit adds a session token and its data to the store, with the
+	// given expiry time. If the session token already exists, then the
+	// data and expiry time are overwritten.
+	Commit(token string, b []byte, expiry time.Time) (err error)
+}
+
+// IterableStore is the interface for stores which support iterating over
+// all (non-expired) sessions that they hold. It is an optional addition to
+// Store, used by SessionManager.Iterate.
+type IterableStore interface {
+	Store
+
+	// All returns a map containing the token and data for all active (non-
+	// expired) sessions in the store.
+	All() (map[string][]byte, error)
+}
+
+// CtxStore is the interface for stores that support context.Context in
+// their methods, so that store operations can be cancelled or carry
+// request-scoped values (such as a database transaction). It is an
+// optional addition to Store; if a configured Store also implements
+// CtxStore, the Ctx* methods are preferred whenever a context is
+// available.
+type CtxStore interface {
+	Store
+
+	DeleteCtx(ctx context.Context, token string) (err error)
+	FindCtx(ctx context.Context, token string) (b []byte, exists bool, err error)
+	CommitCtx(ctx context.Context, token string, b []byte, expiry time.Time) (err error)
+}
+
+// SelfContainedStore is implemented by stores that embed the entire
+// session inside the value that is handed back to the client, rather than
+// persisting it server-side behind a randomly generated lookup token (for
+// example stores/cookiestore). When SessionManager.Store implements this
+// interface, it is used instead of Store/Codec: Seal produces the session
+// cookie's value directly, and Open authenticates and decodes it back.
+// There is nothing to delete server-side, so Destroy never calls Store at
+// all for a SelfContainedStore; it relies solely on expiring the cookie.
+type SelfContainedStore interface {
+	// Seal encodes a session's values into the string that will become the
+	// session cookie's value. deadline is the session's absolute expiry
+	// time (as reported by SessionManager.Deadline) and is carried through
+	// Open unchanged, so that a later Commit can still compute IdleTimeout
+	// relative to it rather than the reduced expiry below. expiry is the
+	// time at which the token itself should stop being honored by Open
+	// (the idle-reduced deadline when IdleTimeout is set, otherwise equal
+	// to deadline); it plays the same role that the backend TTL passed to
+	// Store.Commit plays for a non-self-contained Store.
+	Seal(deadline, expiry time.Time, values map[string]interface{}) (token string, err error)
+
+	// Open authenticates and decodes a token produced by Seal. exists is
+	// false if the token is malformed, fails authentication, or is past
+	// the expiry passed to Seal. The returned deadline is the absolute
+	// deadline that was passed to Seal, not the expiry.
+	Open(token string) (deadline time.Time, values map[string]interface{}, exists bool, err error)
+}
@@ -0,0 +1,225 @@
+// Package boltstore provides a BoltDB-backed implementation of the
+// scs.Store interface, built on top of go.etcd.io/bbolt. Like filestore it
+// requires no external service, but keeps all sessions in a single
+// embedded database file rather than one file per session.
+package boltstore
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// defaultBucket is the bbolt bucket that session data is stored in, unless
+// overridden with NewWithBucket.
+var defaultBucket = []byte("scs_sessions")
+
+// defaultCleanupInterval is how often the background goroutine deletes
+// expired sessions, unless overridden with NewWithCleanupInterval.
+const defaultCleanupInterval = time.Minute
+
+// BoltStore represents the session store.
+type BoltStore struct {
+	db          *bolt.DB
+	bucket      []byte
+	stopCleanup chan bool
+}
+
+// New returns a new BoltStore instance. db must already be open; BoltStore
+// does not take ownership of closing it. A background cleanup goroutine is
+// started, running every minute to remove expired session data.
+func New(db *bolt.DB) (*BoltStore, error) {
+	return NewWithCleanupInterval(db, defaultCleanupInterval)
+}
+
+// NewWithCleanupInterval returns a new BoltStore instance. The
+// cleanupInterval parameter controls how frequently expired session data
+// is removed by the background cleanup goroutine. Setting it to 0
+// disables the background cleanup goroutine.
+func NewWithCleanupInterval(db *bolt.DB, cleanupInterval time.Duration) (*BoltStore, error) {
+	return NewWithBucket(db, defaultBucket, cleanupInterval)
+}
+
+// NewWithBucket is like NewWithCleanupInterval, but lets you specify the
+// bbolt bucket used to store session data.
+func NewWithBucket(db *bolt.DB, bucket []byte, cleanupInterval time.Duration) (*BoltStore, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b := &BoltStore{db: db, bucket: bucket}
+	if cleanupInterval > 0 {
+		go b.startCleanup(cleanupInterval)
+	}
+
+	return b, nil
+}
+
+// Find returns the data for a given session token from the BoltStore
+// instance. If the session token is not found or is expired, the returned
+// exists flag will be false.
+func (b *BoltStore) Find(token string) ([]byte, bool, error) {
+	return b.FindCtx(context.Background(), token)
+}
+
+// Commit adds a session token and data to the BoltStore instance with the
+// given expiry time. If the session token already exists then the data
+// and expiry time are overwritten.
+func (b *BoltStore) Commit(token string, data []byte, expiry time.Time) error {
+	return b.CommitCtx(context.Background(), token, data, expiry)
+}
+
+// Delete removes a session token and its data from the BoltStore instance.
+func (b *BoltStore) Delete(token string) error {
+	return b.DeleteCtx(context.Background(), token)
+}
+
+// FindCtx is like Find, but accepts a context for cancellation.
+func (b *BoltStore) FindCtx(_ context.Context, token string) ([]byte, bool, error) {
+	var expiry time.Time
+	var data []byte
+	var exists bool
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(b.bucket).Get([]byte(token))
+		if v == nil {
+			return nil
+		}
+
+		var decoded []byte
+		var err error
+		expiry, decoded, err = decode(v)
+		if err != nil {
+			return err
+		}
+		// decode's data slice aliases v, which bbolt only guarantees is
+		// valid for the life of this transaction; copy it before it
+		// escapes via the named return values.
+		data = append([]byte(nil), decoded...)
+		exists = true
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if !exists || time.Now().After(expiry) {
+		return nil, false, nil
+	}
+
+	return data, true, nil
+}
+
+// CommitCtx is like Commit, but accepts a context for cancellation.
+func (b *BoltStore) CommitCtx(_ context.Context, token string, data []byte, expiry time.Time) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.bucket).Put([]byte(token), encode(expiry, data))
+	})
+}
+
+// DeleteCtx is like Delete, but accepts a context for cancellation.
+func (b *BoltStore) DeleteCtx(_ context.Context, token string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.bucket).Delete([]byte(token))
+	})
+}
+
+// All returns a map containing the token and data for all active (i.e.
+// not expired) sessions in the BoltStore instance.
+func (b *BoltStore) All() (map[string][]byte, error) {
+	sessions := make(map[string][]byte)
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.bucket).ForEach(func(k, v []byte) error {
+			expiry, data, err := decode(v)
+			if err != nil {
+				return err
+			}
+			if time.Now().After(expiry) {
+				return nil
+			}
+			sessions[string(k)] = append([]byte(nil), data...)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// StopCleanup terminates the background cleanup goroutine, if one is
+// running.
+func (b *BoltStore) StopCleanup() {
+	if b.stopCleanup != nil {
+		b.stopCleanup <- true
+	}
+}
+
+func (b *BoltStore) startCleanup(interval time.Duration) {
+	b.stopCleanup = make(chan bool)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = b.deleteExpired()
+		case <-b.stopCleanup:
+			return
+		}
+	}
+}
+
+func (b *BoltStore) deleteExpired() error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.bucket)
+		var expiredKeys [][]byte
+
+		err := bucket.ForEach(func(k, v []byte) error {
+			expiry, _, err := decode(v)
+			if err != nil || time.Now().After(expiry) {
+				expiredKeys = append(expiredKeys, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range expiredKeys {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func encode(expiry time.Time, data []byte) []byte {
+	header := strconv.FormatInt(expiry.UnixNano(), 10) + "\n"
+	return append([]byte(header), data...)
+}
+
+func decode(v []byte) (time.Time, []byte, error) {
+	i := strings.IndexByte(string(v), '\n')
+	if i < 0 {
+		return time.Time{}, nil, errors.New("boltstore: corrupt session record")
+	}
+
+	nsec, err := strconv.ParseInt(string(v[:i]), 10, 64)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+
+	return time.Unix(0, nsec), v[i+1:], nil
+}
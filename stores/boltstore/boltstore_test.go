@@ -0,0 +1,26 @@
+package boltstore
+
+import (
+	"path/filepath"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/spa5k/scs/stores/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.RunConformance(t, func() storetest.Store {
+		db, err := bolt.Open(filepath.Join(t.TempDir(), "sessions.db"), 0o600, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+
+		store, err := NewWithCleanupInterval(db, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store
+	})
+}
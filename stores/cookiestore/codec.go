@@ -0,0 +1,56 @@
+package cookiestore
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// Codec controls how session values are serialized before sealing, and
+// deserialized after opening.
+type Codec interface {
+	Encode(values map[string]interface{}) ([]byte, error)
+	Decode(b []byte) (map[string]interface{}, error)
+}
+
+// GobCodec serializes session values using encoding/gob. It is the
+// default Codec.
+type GobCodec struct{}
+
+// Encode implements Codec.
+func (GobCodec) Encode(values map[string]interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(&values); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Decode implements Codec.
+func (GobCodec) Decode(b []byte) (map[string]interface{}, error) {
+	var values map[string]interface{}
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// JSONCodec serializes session values using encoding/json. Unlike
+// GobCodec, values decoded back out will be the JSON-native types
+// (float64, []interface{}, map[string]interface{}, and so on) rather than
+// their original Go types, since JSON doesn't carry that information.
+type JSONCodec struct{}
+
+// Encode implements Codec.
+func (JSONCodec) Encode(values map[string]interface{}) ([]byte, error) {
+	return json.Marshal(values)
+}
+
+// Decode implements Codec.
+func (JSONCodec) Decode(b []byte) (map[string]interface{}, error) {
+	var values map[string]interface{}
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,261 @@
+// Package cookiestore provides a stateless implementation of
+// scs.SelfContainedStore: rather than persisting session data server-side
+// behind a random lookup token, the entire session is serialized, sealed
+// and carried inside the session cookie itself. This removes the need for
+// any shared storage, at the cost of a hard ceiling on how much data a
+// session can hold (see CookieStore.MaxCookieSize) and of every request
+// having to ship the full session payload back and forth.
+//
+// It is analogous to gorilla/sessions' NewCookieStore([]byte(key)), but
+// uses an authenticated-encryption envelope (AES-256-GCM by default)
+// instead of a plain signature, and supports rotating keys without
+// invalidating live sessions.
+package cookiestore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+// DefaultMaxCookieSize is the default value of CookieStore.MaxCookieSize:
+// 4 KiB, the practical per-cookie limit described in RFC 6265.
+const DefaultMaxCookieSize = 4096
+
+// ErrCookieTooLarge is returned by Seal when the encoded session would
+// exceed MaxCookieSize.
+var ErrCookieTooLarge = errors.New("cookiestore: sealed session exceeds MaxCookieSize")
+
+// Mode selects the cryptographic construction used to protect the
+// session cookie.
+type Mode int
+
+const (
+	// ModeAEAD seals the session with AES-256-GCM, so its contents are
+	// both authenticated and confidential. This is the default.
+	ModeAEAD Mode = iota
+
+	// ModeHMAC signs the session with HMAC-SHA256 but leaves it otherwise
+	// in plaintext (once base64-decoded). Use this when the session only
+	// holds non-sensitive data and you want it to remain inspectable, at
+	// the cost of confidentiality.
+	ModeHMAC
+)
+
+// CookieStore is a scs.SelfContainedStore that seals the whole session
+// into its cookie value.
+type CookieStore struct {
+	// Keyring supplies the key(s) used to seal and open sessions. The
+	// first key is used for sealing; every key is tried in turn when
+	// opening, so that a key can be rotated out gradually without
+	// invalidating sessions sealed under the old key.
+	Keyring *Keyring
+
+	// Codec controls how session values are serialized before sealing.
+	// Defaults to GobCodec.
+	Codec Codec
+
+	// Mode selects between authenticated encryption (ModeAEAD, the
+	// default) and sign-only (ModeHMAC).
+	Mode Mode
+
+	// MaxCookieSize is the largest sealed cookie value, in bytes, that
+	// Seal will produce. Defaults to DefaultMaxCookieSize (4 KiB).
+	MaxCookieSize int
+}
+
+// New returns a new CookieStore using the given Keyring, AES-256-GCM
+// (ModeAEAD), GobCodec, and DefaultMaxCookieSize.
+func New(keyring *Keyring) *CookieStore {
+	return &CookieStore{
+		Keyring:       keyring,
+		Codec:         GobCodec{},
+		Mode:          ModeAEAD,
+		MaxCookieSize: DefaultMaxCookieSize,
+	}
+}
+
+func (c *CookieStore) codec() Codec {
+	if c.Codec != nil {
+		return c.Codec
+	}
+	return GobCodec{}
+}
+
+func (c *CookieStore) maxCookieSize() int {
+	if c.MaxCookieSize > 0 {
+		return c.MaxCookieSize
+	}
+	return DefaultMaxCookieSize
+}
+
+// Seal implements scs.SelfContainedStore.
+func (c *CookieStore) Seal(deadline, expiry time.Time, values map[string]interface{}) (string, error) {
+	data, err := c.codec().Encode(values)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext := make([]byte, 16+len(data))
+	putUnixNano(plaintext[:8], deadline)
+	putUnixNano(plaintext[8:16], expiry)
+	copy(plaintext[16:], data)
+
+	key := c.Keyring.Current()
+	if key == nil {
+		return "", errors.New("cookiestore: keyring has no keys")
+	}
+
+	var token string
+	switch c.Mode {
+	case ModeHMAC:
+		token = signPlaintext(plaintext, key)
+	default:
+		token, err = sealAEAD(plaintext, key)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if len(token) > c.maxCookieSize() {
+		return "", ErrCookieTooLarge
+	}
+
+	return token, nil
+}
+
+// Open implements scs.SelfContainedStore.
+func (c *CookieStore) Open(token string) (time.Time, map[string]interface{}, bool, error) {
+	var plaintext []byte
+	var ok bool
+
+	for _, key := range c.Keyring.All() {
+		var err error
+		switch c.Mode {
+		case ModeHMAC:
+			plaintext, err = verifyPlaintext(token, key)
+		default:
+			plaintext, err = openAEAD(token, key)
+		}
+		if err == nil {
+			ok = true
+			break
+		}
+	}
+	if !ok || len(plaintext) < 16 {
+		return time.Time{}, nil, false, nil
+	}
+
+	deadline := unixNano(plaintext[:8])
+	expiry := unixNano(plaintext[8:16])
+	if time.Now().After(expiry) {
+		return time.Time{}, nil, false, nil
+	}
+
+	values, err := c.codec().Decode(plaintext[16:])
+	if err != nil {
+		return time.Time{}, nil, false, err
+	}
+
+	return deadline, values, true, nil
+}
+
+func putUnixNano(b []byte, t time.Time) {
+	n := uint64(t.UnixNano())
+	for i := 7; i >= 0; i-- {
+		b[i] = byte(n)
+		n >>= 8
+	}
+}
+
+func unixNano(b []byte) time.Time {
+	var n uint64
+	for i := 0; i < 8; i++ {
+		n = n<<8 | uint64(b[i])
+	}
+	return time.Unix(0, int64(n))
+}
+
+func sealAEAD(plaintext, key []byte) (string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func openAEAD(token string, key []byte) ([]byte, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("cookiestore: sealed value too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func signPlaintext(plaintext, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(plaintext)
+	sig := mac.Sum(nil)
+
+	return base64.RawURLEncoding.EncodeToString(plaintext) + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func verifyPlaintext(token string, key []byte) ([]byte, error) {
+	i := strings.IndexByte(token, '.')
+	if i < 0 {
+		return nil, errors.New("cookiestore: malformed signed token")
+	}
+
+	plaintext, err := base64.RawURLEncoding.DecodeString(token[:i])
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(plaintext)
+	want := mac.Sum(nil)
+
+	if subtle.ConstantTimeCompare(sig, want) != 1 {
+		return nil, errors.New("cookiestore: signature mismatch")
+	}
+
+	return plaintext, nil
+}
+
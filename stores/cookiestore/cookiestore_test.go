@@ -0,0 +1,166 @@
+package cookiestore
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKeyring(t *testing.T) *Keyring {
+	t.Helper()
+	kr, err := NewKeyring(bytes.Repeat([]byte{1}, KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kr
+}
+
+func TestSealAndOpenAEAD(t *testing.T) {
+	store := New(testKeyring(t))
+
+	deadline := time.Now().Add(time.Hour).Truncate(time.Second)
+	token, err := store.Seal(deadline, deadline, map[string]interface{}{"foo": "bar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotDeadline, values, exists, err := store.Open(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists {
+		t.Fatal("want exists to be true")
+	}
+	if !gotDeadline.Equal(deadline) {
+		t.Errorf("want %v; got %v", deadline, gotDeadline)
+	}
+	if values["foo"] != "bar" {
+		t.Errorf("want %q; got %q", "bar", values["foo"])
+	}
+}
+
+func TestSealAndOpenHMAC(t *testing.T) {
+	store := New(testKeyring(t))
+	store.Mode = ModeHMAC
+
+	token, err := store.Seal(time.Now().Add(time.Hour), time.Now().Add(time.Hour), map[string]interface{}{"foo": "bar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, values, exists, err := store.Open(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists || values["foo"] != "bar" {
+		t.Fatalf("want exists=true, foo=bar; got exists=%v, values=%v", exists, values)
+	}
+}
+
+func TestOpenRejectsExpired(t *testing.T) {
+	store := New(testKeyring(t))
+
+	token, err := store.Seal(time.Now().Add(time.Hour), time.Now().Add(-time.Minute), map[string]interface{}{"foo": "bar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, exists, err := store.Open(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Error("want an expired session to not be found")
+	}
+}
+
+func TestOpenReturnsAbsoluteDeadlineNotExpiry(t *testing.T) {
+	store := New(testKeyring(t))
+
+	deadline := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	expiry := time.Now().Add(30 * time.Minute).Truncate(time.Second)
+	token, err := store.Seal(deadline, expiry, map[string]interface{}{"foo": "bar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotDeadline, _, exists, err := store.Open(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists {
+		t.Fatal("want exists to be true")
+	}
+	if !gotDeadline.Equal(deadline) {
+		t.Errorf("want the absolute deadline %v back, not the expiry; got %v", deadline, gotDeadline)
+	}
+}
+
+func TestOpenRejectsTamperedToken(t *testing.T) {
+	store := New(testKeyring(t))
+
+	token, err := store.Seal(time.Now().Add(time.Hour), time.Now().Add(time.Hour), map[string]interface{}{"foo": "bar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := []byte(token)
+	tampered[len(tampered)-1] ^= 0xFF
+
+	_, _, exists, err := store.Open(string(tampered))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Error("want a tampered token to not be found")
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, KeySize)
+	newKey := bytes.Repeat([]byte{2}, KeySize)
+
+	kr, err := NewKeyring(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := New(kr)
+
+	token, err := store.Seal(time.Now().Add(time.Hour), time.Now().Add(time.Hour), map[string]interface{}{"foo": "bar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := kr.Rotate(newKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// A session sealed under the old key must still open.
+	_, values, exists, err := store.Open(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists || values["foo"] != "bar" {
+		t.Fatalf("want exists=true, foo=bar after rotation; got exists=%v, values=%v", exists, values)
+	}
+
+	// New sessions are sealed under the new key.
+	newToken, err := store.Seal(time.Now().Add(time.Hour), time.Now().Add(time.Hour), map[string]interface{}{"foo": "bar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newToken == token {
+		t.Error("want a freshly sealed token to differ after rotation")
+	}
+}
+
+func TestSealRejectsOversizedPayload(t *testing.T) {
+	store := New(testKeyring(t))
+	store.MaxCookieSize = 16
+
+	_, err := store.Seal(time.Now().Add(time.Hour), time.Now().Add(time.Hour), map[string]interface{}{"foo": strings.Repeat("x", 1024)})
+	if err != ErrCookieTooLarge {
+		t.Fatalf("want ErrCookieTooLarge; got %v", err)
+	}
+}
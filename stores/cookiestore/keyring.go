@@ -0,0 +1,93 @@
+package cookiestore
+
+import (
+	"errors"
+	"sync"
+)
+
+// KeySize is the required length, in bytes, of every key held by a
+// Keyring (32 bytes, for use as an AES-256 key or an HMAC-SHA256 key).
+const KeySize = 32
+
+// Keyring holds the current and previous keys used to seal and open
+// cookie-store sessions, so that a key can be rotated without
+// invalidating sessions that were sealed under the previous one.
+type Keyring struct {
+	mu   sync.RWMutex
+	keys [][]byte
+}
+
+// NewKeyring returns a Keyring seeded with the given keys. The first key
+// is treated as current (used for sealing new sessions); any remaining
+// keys are retained only to open sessions sealed under them previously.
+// Every key must be exactly KeySize bytes.
+func NewKeyring(keys ...[]byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("cookiestore: keyring requires at least one key")
+	}
+	for _, k := range keys {
+		if len(k) != KeySize {
+			return nil, errors.New("cookiestore: keys must be 32 bytes")
+		}
+	}
+
+	kr := &Keyring{}
+	kr.keys = append(kr.keys, keys...)
+
+	return kr, nil
+}
+
+// Current returns the key used to seal new sessions, or nil if the
+// keyring holds no keys.
+func (kr *Keyring) Current() []byte {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	if len(kr.keys) == 0 {
+		return nil
+	}
+	return kr.keys[0]
+}
+
+// All returns every key the keyring holds, current key first, for use
+// when trying to open a session sealed under an older key.
+func (kr *Keyring) All() [][]byte {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	keys := make([][]byte, len(kr.keys))
+	copy(keys, kr.keys)
+	return keys
+}
+
+// Rotate makes newKey the current key, demoting the previous current key
+// (and any others) to be tried only when opening existing sessions. It
+// lets operators roll keys on a schedule without forcing every live
+// session to be re-authenticated.
+func (kr *Keyring) Rotate(newKey []byte) error {
+	if len(newKey) != KeySize {
+		return errors.New("cookiestore: keys must be 32 bytes")
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	kr.keys = append([][]byte{newKey}, kr.keys...)
+
+	return nil
+}
+
+// Retire drops the oldest key(s) beyond keep, so that the keyring doesn't
+// grow without bound across many rotations. keep must be at least 1.
+func (kr *Keyring) Retire(keep int) {
+	if keep < 1 {
+		keep = 1
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	if len(kr.keys) > keep {
+		kr.keys = kr.keys[:keep]
+	}
+}
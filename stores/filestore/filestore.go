@@ -0,0 +1,256 @@
+// Package filestore provides a filesystem-backed implementation of the
+// scs.Store interface. Each session is stored as a single file, named
+// after its token, inside a configured directory. It is intended for
+// single-instance deployments that want sessions to survive a process
+// restart without taking on an external dependency.
+package filestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultCleanupInterval is how often the background goroutine deletes
+// expired session files, unless overridden with NewWithCleanupInterval.
+const defaultCleanupInterval = time.Minute
+
+// FileStore represents the session store.
+type FileStore struct {
+	dir         string
+	stopCleanup chan bool
+}
+
+// New returns a new FileStore instance using dir to store session files.
+// The directory must already exist. A background cleanup goroutine is
+// started, running every minute to remove expired session files.
+func New(dir string) (*FileStore, error) {
+	return NewWithCleanupInterval(dir, defaultCleanupInterval)
+}
+
+// NewWithCleanupInterval returns a new FileStore instance. The
+// cleanupInterval parameter controls how frequently expired session files
+// are removed by the background cleanup goroutine. Setting it to 0
+// disables the background cleanup goroutine.
+func NewWithCleanupInterval(dir string, cleanupInterval time.Duration) (*FileStore, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("filestore: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("filestore: %q is not a directory", dir)
+	}
+
+	f := &FileStore{dir: dir}
+	if cleanupInterval > 0 {
+		go f.startCleanup(cleanupInterval)
+	}
+
+	return f, nil
+}
+
+// Find returns the data for a given session token from the FileStore
+// instance. If the session token is not found or is expired, the returned
+// exists flag will be false.
+func (f *FileStore) Find(token string) ([]byte, bool, error) {
+	return f.FindCtx(context.Background(), token)
+}
+
+// Commit adds a session token and data to the FileStore instance with the
+// given expiry time. If the session token already exists then the data
+// and expiry time are overwritten. The write is atomic: data is written
+// to a temporary file in the same directory and then renamed into place,
+// so a concurrent Find will never observe a partial write.
+func (f *FileStore) Commit(token string, b []byte, expiry time.Time) error {
+	return f.CommitCtx(context.Background(), token, b, expiry)
+}
+
+// Delete removes a session token and its data from the FileStore instance.
+func (f *FileStore) Delete(token string) error {
+	return f.DeleteCtx(context.Background(), token)
+}
+
+// FindCtx is like Find, but accepts a context for cancellation.
+func (f *FileStore) FindCtx(_ context.Context, token string) ([]byte, bool, error) {
+	path, err := f.path(token)
+	if err != nil {
+		return nil, false, err
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	expiry, data, err := decode(b)
+	if err != nil {
+		return nil, false, err
+	}
+	if time.Now().After(expiry) {
+		return nil, false, nil
+	}
+
+	return data, true, nil
+}
+
+// CommitCtx is like Commit, but accepts a context for cancellation.
+func (f *FileStore) CommitCtx(_ context.Context, token string, b []byte, expiry time.Time) error {
+	path, err := f.path(token)
+	if err != nil {
+		return err
+	}
+
+	// Each Commit writes to its own uniquely named temporary file, so two
+	// concurrent Commits for the same token never write into one file.
+	tmp, err := os.CreateTemp(f.dir, token+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(encode(expiry, b)); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// DeleteCtx is like Delete, but accepts a context for cancellation.
+func (f *FileStore) DeleteCtx(_ context.Context, token string) error {
+	path, err := f.path(token)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// All returns a map containing the token and data for all active (i.e.
+// not expired) sessions in the FileStore instance.
+func (f *FileStore) All() (map[string][]byte, error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make(map[string][]byte)
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".tmp") {
+			continue
+		}
+
+		b, err := os.ReadFile(filepath.Join(f.dir, entry.Name()))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		expiry, data, err := decode(b)
+		if err != nil {
+			return nil, err
+		}
+		if time.Now().After(expiry) {
+			continue
+		}
+
+		sessions[entry.Name()] = data
+	}
+
+	return sessions, nil
+}
+
+// StopCleanup terminates the background cleanup goroutine, if one is
+// running.
+func (f *FileStore) StopCleanup() {
+	if f.stopCleanup != nil {
+		f.stopCleanup <- true
+	}
+}
+
+func (f *FileStore) startCleanup(interval time.Duration) {
+	f.stopCleanup = make(chan bool)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			f.deleteExpired()
+		case <-f.stopCleanup:
+			return
+		}
+	}
+}
+
+func (f *FileStore) deleteExpired() {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".tmp") {
+			continue
+		}
+
+		path := filepath.Join(f.dir, entry.Name())
+		b, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		expiry, _, err := decode(b)
+		if err != nil || time.Now().After(expiry) {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// path returns the filesystem path for a session token, rejecting tokens
+// that would escape f.dir.
+func (f *FileStore) path(token string) (string, error) {
+	if token == "" || token != filepath.Base(token) {
+		return "", fmt.Errorf("filestore: invalid session token %q", token)
+	}
+	return filepath.Join(f.dir, token), nil
+}
+
+// encode prefixes the session data with its expiry time, formatted as a
+// base-10 Unix nanosecond timestamp followed by a newline, so that Find
+// doesn't need a second file (or a stat call) to check expiry.
+func encode(expiry time.Time, data []byte) []byte {
+	header := strconv.FormatInt(expiry.UnixNano(), 10) + "\n"
+	return append([]byte(header), data...)
+}
+
+func decode(b []byte) (time.Time, []byte, error) {
+	i := strings.IndexByte(string(b), '\n')
+	if i < 0 {
+		return time.Time{}, nil, errors.New("filestore: corrupt session file")
+	}
+
+	nsec, err := strconv.ParseInt(string(b[:i]), 10, 64)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("filestore: corrupt session file: %w", err)
+	}
+
+	return time.Unix(0, nsec), b[i+1:], nil
+}
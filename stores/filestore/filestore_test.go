@@ -0,0 +1,17 @@
+package filestore
+
+import (
+	"testing"
+
+	"github.com/spa5k/scs/stores/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.RunConformance(t, func() storetest.Store {
+		store, err := NewWithCleanupInterval(t.TempDir(), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store
+	})
+}
@@ -0,0 +1,167 @@
+// Package mysqlstore provides a MySQL-backed implementation of the
+// scs.Store interface, built on top of database/sql and (typically)
+// github.com/go-sql-driver/mysql.
+//
+// The *sql.DB passed to New must already be connected to a database with a
+// sessions table created with the following structure (the table name can
+// be changed with NewWithTable):
+//
+//	CREATE TABLE sessions (
+//		token CHAR(52) PRIMARY KEY,
+//		data BLOB NOT NULL,
+//		expiry TIMESTAMP(6) NOT NULL,
+//		KEY sessions_expiry_idx (expiry)
+//	);
+package mysqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// defaultCleanupInterval is how often the background goroutine deletes
+// expired sessions, unless overridden with NewWithCleanupInterval.
+const defaultCleanupInterval = 5 * time.Minute
+
+// MySQLStore represents the session store.
+type MySQLStore struct {
+	db          *sql.DB
+	table       string
+	stopCleanup chan bool
+}
+
+// New returns a new MySQLStore instance, with a background cleanup
+// goroutine that runs every 5 minutes to remove expired session data.
+func New(db *sql.DB) *MySQLStore {
+	return NewWithCleanupInterval(db, defaultCleanupInterval)
+}
+
+// NewWithCleanupInterval returns a new MySQLStore instance. The
+// cleanupInterval parameter controls how frequently expired session data
+// is removed by the background cleanup goroutine. Setting it to 0
+// disables the background cleanup goroutine.
+func NewWithCleanupInterval(db *sql.DB, cleanupInterval time.Duration) *MySQLStore {
+	return NewWithTable(db, "sessions", cleanupInterval)
+}
+
+// NewWithTable is like NewWithCleanupInterval, but lets you specify the
+// name of the table used to store session data.
+func NewWithTable(db *sql.DB, table string, cleanupInterval time.Duration) *MySQLStore {
+	m := &MySQLStore{db: db, table: table}
+	if cleanupInterval > 0 {
+		go m.startCleanup(cleanupInterval)
+	}
+	return m
+}
+
+// Find returns the data for a given session token from the MySQLStore
+// instance. If the session token is not found or is expired, the returned
+// exists flag will be false.
+func (m *MySQLStore) Find(token string) ([]byte, bool, error) {
+	return m.FindCtx(context.Background(), token)
+}
+
+// Commit adds a session token and data to the MySQLStore instance with the
+// given expiry time. If the session token already exists then the data
+// and expiry time are updated.
+func (m *MySQLStore) Commit(token string, b []byte, expiry time.Time) error {
+	return m.CommitCtx(context.Background(), token, b, expiry)
+}
+
+// Delete removes a session token and its data from the MySQLStore
+// instance.
+func (m *MySQLStore) Delete(token string) error {
+	return m.DeleteCtx(context.Background(), token)
+}
+
+// FindCtx is like Find, but accepts a context for cancellation.
+func (m *MySQLStore) FindCtx(ctx context.Context, token string) ([]byte, bool, error) {
+	var b []byte
+
+	query := `SELECT data FROM ` + m.table + ` WHERE token = ? AND expiry > UTC_TIMESTAMP(6)`
+
+	err := m.db.QueryRowContext(ctx, query, token).Scan(&b)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return b, true, nil
+}
+
+// CommitCtx is like Commit, but accepts a context for cancellation.
+func (m *MySQLStore) CommitCtx(ctx context.Context, token string, b []byte, expiry time.Time) error {
+	query := `REPLACE INTO ` + m.table + ` (token, data, expiry) VALUES (?, ?, ?)`
+
+	_, err := m.db.ExecContext(ctx, query, token, b, expiry.UTC())
+	return err
+}
+
+// DeleteCtx is like Delete, but accepts a context for cancellation.
+func (m *MySQLStore) DeleteCtx(ctx context.Context, token string) error {
+	query := `DELETE FROM ` + m.table + ` WHERE token = ?`
+
+	_, err := m.db.ExecContext(ctx, query, token)
+	return err
+}
+
+// All returns a map containing the token and data for all active (i.e.
+// not expired) sessions in the MySQLStore instance.
+func (m *MySQLStore) All() (map[string][]byte, error) {
+	ctx := context.Background()
+
+	query := `SELECT token, data FROM ` + m.table + ` WHERE expiry > UTC_TIMESTAMP(6)`
+
+	rows, err := m.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make(map[string][]byte)
+	for rows.Next() {
+		var token string
+		var b []byte
+		if err := rows.Scan(&token, &b); err != nil {
+			return nil, err
+		}
+		sessions[token] = b
+	}
+
+	return sessions, rows.Err()
+}
+
+// StopCleanup terminates the background cleanup goroutine, if one is
+// running. It should be called before the MySQLStore is garbage
+// collected, to avoid a memory leak.
+func (m *MySQLStore) StopCleanup() {
+	if m.stopCleanup != nil {
+		m.stopCleanup <- true
+	}
+}
+
+func (m *MySQLStore) startCleanup(interval time.Duration) {
+	m.stopCleanup = make(chan bool)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.deleteExpired(); err != nil {
+				continue
+			}
+		case <-m.stopCleanup:
+			return
+		}
+	}
+}
+
+func (m *MySQLStore) deleteExpired() error {
+	query := `DELETE FROM ` + m.table + ` WHERE expiry <= UTC_TIMESTAMP(6)`
+	_, err := m.db.ExecContext(context.Background(), query)
+	return err
+}
@@ -0,0 +1,35 @@
+package mysqlstore
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/go-sql-driver/mysql"
+
+	"github.com/spa5k/scs/stores/storetest"
+)
+
+// TestConformance requires a reachable MySQL instance, configured via the
+// SCS_TEST_MYSQL_DSN environment variable, with the sessions table
+// documented in the package comment already created. It is skipped
+// otherwise. The sessions table is emptied before each sub-test.
+func TestConformance(t *testing.T) {
+	dsn := os.Getenv("SCS_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("SCS_TEST_MYSQL_DSN not set; skipping mysqlstore conformance test")
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	storetest.RunConformance(t, func() storetest.Store {
+		if _, err := db.Exec(`DELETE FROM sessions`); err != nil {
+			t.Fatal(err)
+		}
+		return NewWithCleanupInterval(db, 0)
+	})
+}
@@ -0,0 +1,169 @@
+// Package pgxstore provides a PostgreSQL-backed implementation of the
+// scs.Store interface, built on top of github.com/jackc/pgx/v5/pgxpool.
+//
+// The pool passed to New must already be connected to a database with a
+// sessions table created with the following structure (the table name can
+// be changed with NewWithTable):
+//
+//	CREATE TABLE sessions (
+//		token TEXT PRIMARY KEY,
+//		data BYTEA NOT NULL,
+//		expiry TIMESTAMPTZ NOT NULL
+//	);
+//
+//	CREATE INDEX sessions_expiry_idx ON sessions (expiry);
+package pgxstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// defaultCleanupInterval is how often the background goroutine deletes
+// expired sessions, unless overridden with NewWithCleanupInterval.
+const defaultCleanupInterval = 5 * time.Minute
+
+// PgxStore represents the session store.
+type PgxStore struct {
+	pool        *pgxpool.Pool
+	table       string
+	stopCleanup chan bool
+}
+
+// New returns a new PgxStore instance, with a background cleanup goroutine
+// that runs every 5 minutes to remove expired session data.
+func New(pool *pgxpool.Pool) *PgxStore {
+	return NewWithCleanupInterval(pool, defaultCleanupInterval)
+}
+
+// NewWithCleanupInterval returns a new PgxStore instance. The
+// cleanupInterval parameter controls how frequently expired session data
+// is removed by the background cleanup goroutine. Setting it to 0
+// disables the background cleanup goroutine.
+func NewWithCleanupInterval(pool *pgxpool.Pool, cleanupInterval time.Duration) *PgxStore {
+	return NewWithTable(pool, "sessions", cleanupInterval)
+}
+
+// NewWithTable is like NewWithCleanupInterval, but lets you specify the
+// name of the table used to store session data.
+func NewWithTable(pool *pgxpool.Pool, table string, cleanupInterval time.Duration) *PgxStore {
+	p := &PgxStore{pool: pool, table: table}
+	if cleanupInterval > 0 {
+		go p.startCleanup(cleanupInterval)
+	}
+	return p
+}
+
+// Find returns the data for a given session token from the PgxStore
+// instance. If the session token is not found or is expired, the returned
+// exists flag will be false.
+func (p *PgxStore) Find(token string) ([]byte, bool, error) {
+	return p.FindCtx(context.Background(), token)
+}
+
+// Commit adds a session token and data to the PgxStore instance with the
+// given expiry time. If the session token already exists then the data
+// and expiry time are updated.
+func (p *PgxStore) Commit(token string, b []byte, expiry time.Time) error {
+	return p.CommitCtx(context.Background(), token, b, expiry)
+}
+
+// Delete removes a session token and its data from the PgxStore instance.
+func (p *PgxStore) Delete(token string) error {
+	return p.DeleteCtx(context.Background(), token)
+}
+
+// FindCtx is like Find, but accepts a context for cancellation.
+func (p *PgxStore) FindCtx(ctx context.Context, token string) ([]byte, bool, error) {
+	var b []byte
+
+	query := `SELECT data FROM ` + p.table + ` WHERE token = $1 AND expiry > now()`
+
+	err := p.pool.QueryRow(ctx, query, token).Scan(&b)
+	if err == pgx.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return b, true, nil
+}
+
+// CommitCtx is like Commit, but accepts a context for cancellation.
+func (p *PgxStore) CommitCtx(ctx context.Context, token string, b []byte, expiry time.Time) error {
+	query := `INSERT INTO ` + p.table + ` (token, data, expiry) VALUES ($1, $2, $3)
+		ON CONFLICT (token) DO UPDATE SET data = EXCLUDED.data, expiry = EXCLUDED.expiry`
+
+	_, err := p.pool.Exec(ctx, query, token, b, expiry)
+	return err
+}
+
+// DeleteCtx is like Delete, but accepts a context for cancellation.
+func (p *PgxStore) DeleteCtx(ctx context.Context, token string) error {
+	query := `DELETE FROM ` + p.table + ` WHERE token = $1`
+
+	_, err := p.pool.Exec(ctx, query, token)
+	return err
+}
+
+// All returns a map containing the token and data for all active (i.e.
+// not expired) sessions in the PgxStore instance.
+func (p *PgxStore) All() (map[string][]byte, error) {
+	ctx := context.Background()
+
+	query := `SELECT token, data FROM ` + p.table + ` WHERE expiry > now()`
+
+	rows, err := p.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make(map[string][]byte)
+	for rows.Next() {
+		var token string
+		var b []byte
+		if err := rows.Scan(&token, &b); err != nil {
+			return nil, err
+		}
+		sessions[token] = b
+	}
+
+	return sessions, rows.Err()
+}
+
+// StopCleanup terminates the background cleanup goroutine, if one is
+// running. It should be called before the PgxStore is garbage collected,
+// to avoid a memory leak.
+func (p *PgxStore) StopCleanup() {
+	if p.stopCleanup != nil {
+		p.stopCleanup <- true
+	}
+}
+
+func (p *PgxStore) startCleanup(interval time.Duration) {
+	p.stopCleanup = make(chan bool)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.deleteExpired(); err != nil {
+				continue
+			}
+		case <-p.stopCleanup:
+			return
+		}
+	}
+}
+
+func (p *PgxStore) deleteExpired() error {
+	query := `DELETE FROM ` + p.table + ` WHERE expiry <= now()`
+	_, err := p.pool.Exec(context.Background(), query)
+	return err
+}
@@ -0,0 +1,37 @@
+package pgxstore
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/spa5k/scs/stores/storetest"
+)
+
+// TestConformance requires a reachable PostgreSQL instance, configured via
+// the SCS_TEST_POSTGRES_URL environment variable, with the sessions table
+// documented in the package comment already created. It is skipped
+// otherwise. The sessions table is emptied before each sub-test.
+func TestConformance(t *testing.T) {
+	url := os.Getenv("SCS_TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("SCS_TEST_POSTGRES_URL not set; skipping pgxstore conformance test")
+	}
+
+	ctx := context.Background()
+
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	storetest.RunConformance(t, func() storetest.Store {
+		if _, err := pool.Exec(ctx, `DELETE FROM sessions`); err != nil {
+			t.Fatal(err)
+		}
+		return NewWithCleanupInterval(pool, 0)
+	})
+}
@@ -0,0 +1,115 @@
+// Package redisstore provides a Redis-backed implementation of the
+// scs.Store interface, built on top of github.com/redis/go-redis/v9.
+// Session expiry is delegated entirely to Redis's native key TTL, so there
+// is no background cleanup goroutine to manage.
+package redisstore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultPrefix is prepended to every session token to form the Redis key,
+// so that session data can share a keyspace with other application data
+// without colliding.
+const defaultPrefix = "scs:session:"
+
+// RedisStore represents the session store.
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// New returns a new RedisStore instance. The client parameter should be a
+// pool that is setup and managed by the caller (typically
+// *redis.Client or *redis.ClusterClient).
+func New(client redis.UniversalClient) *RedisStore {
+	return NewWithPrefix(client, defaultPrefix)
+}
+
+// NewWithPrefix returns a new RedisStore instance with a custom key
+// prefix, which is useful when multiple applications share the same Redis
+// instance.
+func NewWithPrefix(client redis.UniversalClient, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Find returns the data for a given session token from the RedisStore
+// instance. If the session token is not found or is expired, the returned
+// exists flag will be false.
+func (r *RedisStore) Find(token string) ([]byte, bool, error) {
+	return r.FindCtx(context.Background(), token)
+}
+
+// Commit adds a session token and data to the RedisStore instance with the
+// given expiry time. The expiry is applied as the key's TTL, so Redis
+// reclaims the memory itself once it elapses.
+func (r *RedisStore) Commit(token string, b []byte, expiry time.Time) error {
+	return r.CommitCtx(context.Background(), token, b, expiry)
+}
+
+// Delete removes a session token and its data from the RedisStore
+// instance.
+func (r *RedisStore) Delete(token string) error {
+	return r.DeleteCtx(context.Background(), token)
+}
+
+// FindCtx is like Find, but accepts a context for cancellation.
+func (r *RedisStore) FindCtx(ctx context.Context, token string) ([]byte, bool, error) {
+	b, err := r.client.Get(ctx, r.prefix+token).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return b, true, nil
+}
+
+// CommitCtx is like Commit, but accepts a context for cancellation.
+func (r *RedisStore) CommitCtx(ctx context.Context, token string, b []byte, expiry time.Time) error {
+	ttl := time.Until(expiry)
+	if ttl <= 0 {
+		return r.DeleteCtx(ctx, token)
+	}
+
+	return r.client.Set(ctx, r.prefix+token, b, ttl).Err()
+}
+
+// DeleteCtx is like Delete, but accepts a context for cancellation.
+func (r *RedisStore) DeleteCtx(ctx context.Context, token string) error {
+	return r.client.Del(ctx, r.prefix+token).Err()
+}
+
+// All returns a map containing the token and data for all active (i.e.
+// not expired) sessions in the RedisStore instance. It is implemented as
+// an incremental SCAN over the store's key prefix, so it is safe to run
+// against a live, busy keyspace.
+func (r *RedisStore) All() (map[string][]byte, error) {
+	ctx := context.Background()
+	sessions := make(map[string][]byte)
+
+	iter := r.client.Scan(ctx, 0, r.prefix+"*", 0).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+
+		b, err := r.client.Get(ctx, key).Bytes()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		sessions[strings.TrimPrefix(key, r.prefix)] = b
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
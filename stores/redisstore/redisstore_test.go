@@ -0,0 +1,34 @@
+package redisstore
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/spa5k/scs/stores/storetest"
+)
+
+// TestConformance requires a reachable Redis instance, configured via the
+// SCS_TEST_REDIS_URL environment variable (e.g.
+// "redis://localhost:6379/0"). It is skipped otherwise. Each sub-test gets
+// its own key prefix, so it starts from an empty store.
+func TestConformance(t *testing.T) {
+	url := os.Getenv("SCS_TEST_REDIS_URL")
+	if url == "" {
+		t.Skip("SCS_TEST_REDIS_URL not set; skipping redisstore conformance test")
+	}
+
+	opt, err := redis.ParseURL(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := redis.NewClient(opt)
+	t.Cleanup(func() { _ = client.Close() })
+
+	storetest.RunConformance(t, func() storetest.Store {
+		return NewWithPrefix(client, fmt.Sprintf("scs:test:%s:%d:", t.Name(), time.Now().UnixNano()))
+	})
+}
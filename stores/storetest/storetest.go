@@ -0,0 +1,171 @@
+// Package storetest provides a conformance test suite shared by every
+// backend under stores/*, so that each implementation of the scs.Store
+// contract is exercised the same way regardless of where the data actually
+// lives.
+package storetest
+
+import (
+	"testing"
+	"time"
+)
+
+// Store is the subset of scs.Store exercised by RunConformance. It is
+// declared locally, rather than imported from the root scs package, so
+// that storetest (and the backends that depend on it) have no import
+// dependency on scs itself - the same convention every stores/* package
+// follows.
+type Store interface {
+	Find(token string) (b []byte, exists bool, err error)
+	Commit(token string, b []byte, expiry time.Time) error
+	Delete(token string) error
+}
+
+// IterableStore is the subset of scs.IterableStore exercised by
+// RunConformance's "All" sub-test. Declared locally for the same reason as
+// Store above.
+type IterableStore interface {
+	Store
+	All() (map[string][]byte, error)
+}
+
+// RunConformance exercises the basic Store contract - committing and
+// finding data, overwriting an existing token, deleting a token, and
+// expiry - against a fresh Store returned by newStore for each sub-test.
+// Backends call this from their own _test.go files, typically skipping
+// the test up front when the backing service isn't reachable from the
+// current environment.
+func RunConformance(t *testing.T, newStore func() Store) {
+	t.Helper()
+
+	t.Run("CommitAndFind", func(t *testing.T) {
+		store := newStore()
+
+		if err := store.Commit("session_token", []byte("encoded_data"), time.Now().Add(time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+
+		b, exists, err := store.Find("session_token")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !exists {
+			t.Fatal("want exists to be true")
+		}
+		if string(b) != "encoded_data" {
+			t.Errorf("want %q; got %q", "encoded_data", b)
+		}
+	})
+
+	t.Run("Overwrite", func(t *testing.T) {
+		store := newStore()
+
+		if err := store.Commit("token", []byte("one"), time.Now().Add(time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Commit("token", []byte("two"), time.Now().Add(time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+
+		b, exists, err := store.Find("token")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !exists || string(b) != "two" {
+			t.Errorf("want %q, true; got %q, %v", "two", b, exists)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		store := newStore()
+
+		if err := store.Commit("token", []byte("data"), time.Now().Add(time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Delete("token"); err != nil {
+			t.Fatal(err)
+		}
+
+		_, exists, err := store.Find("token")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exists {
+			t.Error("want exists to be false after Delete")
+		}
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		store := newStore()
+
+		if err := store.Commit("token", []byte("data"), time.Now().Add(-time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+
+		_, exists, err := store.Find("token")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exists {
+			t.Error("want an expired session to not be found")
+		}
+	})
+
+	t.Run("MissingToken", func(t *testing.T) {
+		store := newStore()
+
+		_, exists, err := store.Find("does-not-exist")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exists {
+			t.Error("want exists to be false for a token that was never committed")
+		}
+	})
+
+	t.Run("All", func(t *testing.T) {
+		store := newStore()
+
+		iterableStore, ok := store.(IterableStore)
+		if !ok {
+			t.Skipf("%T does not implement IterableStore", store)
+		}
+
+		if err := iterableStore.Commit("active_one", []byte("one"), time.Now().Add(time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		if err := iterableStore.Commit("active_two", []byte("two"), time.Now().Add(time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		if err := iterableStore.Commit("expired", []byte("stale"), time.Now().Add(-time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+
+		all, err := iterableStore.All()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all) != 2 {
+			t.Fatalf("want 2 active sessions; got %d: %v", len(all), all)
+		}
+		if string(all["active_one"]) != "one" {
+			t.Errorf("want %q; got %q", "one", all["active_one"])
+		}
+		if string(all["active_two"]) != "two" {
+			t.Errorf("want %q; got %q", "two", all["active_two"])
+		}
+		if _, exists := all["expired"]; exists {
+			t.Error("want an expired session to be excluded from All")
+		}
+
+		if err := iterableStore.Delete("active_one"); err != nil {
+			t.Fatal(err)
+		}
+		all, err = iterableStore.All()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, exists := all["active_one"]; exists {
+			t.Error("want a deleted session to be excluded from All")
+		}
+	})
+}